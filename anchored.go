@@ -0,0 +1,57 @@
+package orca
+
+import (
+	"github.com/pkg/errors"
+)
+
+// CanonizeAnchored returns the canonical form of G relative to the given ordered anchor vertices (e.g. attachment
+// points), where anchors[i] is assigned canonic VtxLabel i+1 and only the remaining vertices are canonically
+// relabeled.  Two graphs have the same anchored form only if an isomorphism between them maps anchors to anchors in
+// order.
+//
+// Anchors are individualized by giving anchor i the reserved VtxColor -(i+1) during canonization, while the returned
+// form has the anchors' own colors.  Client colors must be >= 0 (see VtxColor), so none can be confused with an
+// anchor, and a negative one is an error.
+func CanonizeAnchored(canonizer IGraphCanonizer, G *Graph, anchors []VtxLabel) (*Canonic, error) {
+	anchorIdx := make(map[VtxLabel]int, len(anchors))
+	for i, vi := range anchors {
+		if _, dupe := anchorIdx[vi]; dupe {
+			return nil, errors.Errorf("anchor %d is given more than once", vi)
+		}
+		anchorIdx[vi] = i
+	}
+
+	individualized := &Graph{
+		Vtx:   make([]Vtx, len(G.Vtx)),
+		Edges: G.Edges,
+	}
+	found := 0
+	for i, v := range G.Vtx {
+		if v.Color < 0 {
+			return nil, errors.Errorf("VtxLabel %d has negative VtxColor %d, which is reserved for anchors", v.Label, v.Color)
+		}
+		if idx, isAnchor := anchorIdx[v.Label]; isAnchor {
+			v.Color = VtxColor(-(idx + 1))
+			found++
+		}
+		individualized.Vtx[i] = v
+	}
+	if found != len(anchors) {
+		return nil, errors.New("anchor VtxLabel not found in graph")
+	}
+
+	C, err := CanonizeGraph(canonizer, individualized)
+	if err != nil {
+		return nil, err
+	}
+
+	// The labeling is canonic for the anchored graph, so the order of the remaining vertices within it is too
+	labeling := make([]VtxLabel, 0, len(C.Labeling))
+	labeling = append(labeling, anchors...)
+	for _, vi := range C.Labeling {
+		if _, isAnchor := anchorIdx[vi]; !isAnchor {
+			labeling = append(labeling, vi)
+		}
+	}
+	return relabel(G, labeling), nil
+}
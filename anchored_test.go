@@ -0,0 +1,57 @@
+package orca
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCanonizeAnchored(t *testing.T) {
+	canonizer := NewCanonizer(DefaultCanonizerOpts)
+	anchored := func(text string, anchors ...VtxLabel) *Canonic {
+		G, err := ParseGraphText(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		C, err := CanonizeAnchored(canonizer, G, anchors)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, vi := range anchors {
+			if C.Labeling[i] != vi {
+				t.Fatalf("expected anchor %d to be labeled %d", vi, i+1)
+			}
+		}
+		return C
+	}
+
+	// A colored chain C-C-O-C: anchoring either carbon end differs, but not the labeling used
+	end1 := anchored("v3:8 1-2 2-3 3-4", 1)
+	end1b := anchored("v2:8 4-3 3-2 2-1", 4)
+	if !bytes.Equal(end1.Encoding(), end1b.Encoding()) {
+		t.Fatalf("expected equal anchored forms, got %v and %v", end1.Graph.String(), end1b.Graph.String())
+	}
+	end4 := anchored("v3:8 1-2 2-3 3-4", 4)
+	if bytes.Equal(end1.Encoding(), end4.Encoding()) {
+		t.Fatal("expected anchoring different ends to give different forms")
+	}
+
+	// The order of anchors matters unless a symmetry swaps them
+	if bytes.Equal(anchored("v3:8 1-2 2-3 3-4", 1, 4).Encoding(), anchored("v3:8 1-2 2-3 3-4", 4, 1).Encoding()) {
+		t.Fatal("expected anchor order to matter")
+	}
+	if !bytes.Equal(anchored("1-2 2-3 3-4", 1, 4).Encoding(), anchored("1-2 2-3 3-4", 4, 1).Encoding()) {
+		t.Fatal("expected symmetric anchors to give the same form")
+	}
+
+	G, _ := ParseGraphText("1-2")
+	if _, err := CanonizeAnchored(canonizer, G, []VtxLabel{3}); err == nil {
+		t.Fatal("expected an error for a missing anchor")
+	}
+	if _, err := CanonizeAnchored(canonizer, G, []VtxLabel{1, 1}); err == nil {
+		t.Fatal("expected an error for a repeated anchor")
+	}
+	G.Vtx[1].Color = -1
+	if _, err := CanonizeAnchored(canonizer, G, []VtxLabel{1}); err == nil {
+		t.Fatal("expected an error for a negative client color")
+	}
+}
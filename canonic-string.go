@@ -0,0 +1,98 @@
+package orca
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+/* Canonic strings:
+
+A canonic string is a human-readable (and URL-safe) identifier for a canonical GraphEncoding:
+
+    o1 <Base32Encoding of GraphEncoding> <check char>
+
+"o" never appears in GeohashBase32Alphabet, so the prefix can't be confused with payload.  "1" is the format version.
+The trailing check char is the Luhn mod 32 check symbol over the payload, catching any single mistyped char and
+most adjacent transpositions.
+*/
+
+// CanonicStringPrefix starts every canonic string and denotes its format version.
+const CanonicStringPrefix = "o1"
+
+var (
+	ErrBadCanonicString = errors.New("bad canonic string")
+)
+
+// FormatCanonicString returns the canonic string for the given encoding (see Canonic.Encoding)
+func FormatCanonicString(Genc GraphEncoding) string {
+	payload := Base32Encoding.EncodeToString(Genc)
+
+	str := strings.Builder{}
+	str.Grow(len(CanonicStringPrefix) + len(payload) + 1)
+	str.WriteString(CanonicStringPrefix)
+	str.WriteString(payload)
+	str.WriteByte(GeohashBase32Alphabet[luhn32CheckDigit(payload)])
+	return str.String()
+}
+
+// ParseCanonicString verifies and decodes a string made by FormatCanonicString.
+// Uppercase input is accepted since spreadsheets and such like to change case.
+func ParseCanonicString(str string) (GraphEncoding, error) {
+	str = strings.ToLower(strings.TrimSpace(str))
+
+	if !strings.HasPrefix(str, CanonicStringPrefix) {
+		return nil, errors.Wrapf(ErrBadCanonicString, "missing %q prefix", CanonicStringPrefix)
+	}
+	body := str[len(CanonicStringPrefix):]
+	if len(body) < 1 {
+		return nil, errors.Wrap(ErrBadCanonicString, "missing check char")
+	}
+	payload := body[:len(body)-1]
+	check := strings.IndexByte(GeohashBase32Alphabet, body[len(body)-1])
+	if check < 0 {
+		return nil, errors.Wrapf(ErrBadCanonicString, "invalid check char %q", body[len(body)-1])
+	}
+	for i := 0; i < len(payload); i++ {
+		if strings.IndexByte(GeohashBase32Alphabet, payload[i]) < 0 {
+			return nil, errors.Wrapf(ErrBadCanonicString, "invalid char %q at offset %v", payload[i], len(CanonicStringPrefix)+i)
+		}
+	}
+	if luhn32CheckDigit(payload) != check {
+		return nil, errors.Wrap(ErrBadCanonicString, "checksum mismatch")
+	}
+
+	Genc, err := Base32Encoding.DecodeString(payload)
+	if err != nil {
+		return nil, errors.Wrap(ErrBadCanonicString, err.Error())
+	}
+	return GraphEncoding(Genc), nil
+}
+
+// ParseCanonicGraph parses a canonic string and inflates the (canonic) graph it encodes.
+func ParseCanonicGraph(str string) (*Graph, error) {
+	Genc, err := ParseCanonicString(str)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeGraph(Genc)
+}
+
+// CanonicString returns the canonic string for C (see FormatCanonicString)
+func (C *Canonic) CanonicString() string {
+	return FormatCanonicString(C.Encoding())
+}
+
+// luhn32CheckDigit returns the Luhn mod N check symbol (N = 32) for the given Base32Encoding payload.
+func luhn32CheckDigit(payload string) int {
+	const N = 32
+
+	factor := 2
+	sum := 0
+	for i := len(payload) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(GeohashBase32Alphabet, payload[i])
+		factor = 3 - factor
+		sum += addend/N + addend%N
+	}
+	return (N - sum%N) % N
+}
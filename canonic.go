@@ -0,0 +1,218 @@
+package orca
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Graph holds a graph in slices and is the non-streaming equivalent of GraphIn and GraphOut.
+type Graph struct {
+	Vtx   []Vtx
+	Edges []Edge
+}
+
+// ReadGraph consumes Gin until its end is signaled, returning everything received.
+func ReadGraph(Gin GraphIn) *Graph {
+	G := &Graph{}
+	Gin.Consume(func(v Vtx, e Edge) {
+		if v.Label != 0 {
+			G.Vtx = append(G.Vtx, v)
+		} else {
+			G.Edges = append(G.Edges, e)
+		}
+	})
+	return G
+}
+
+// Export sends all of G's vertices and edges to Gout and then signals the end of the graph.
+func (G *Graph) Export(Gout GraphOut) {
+	for _, v := range G.Vtx {
+		Gout.Vtx <- v
+	}
+	for _, e := range G.Edges {
+		Gout.Edges <- e
+	}
+	Gout.Break()
+}
+
+func (G *Graph) NumVerts() int {
+	return len(G.Vtx)
+}
+
+func (G *Graph) NumEdges() int {
+	return len(G.Edges)
+}
+
+// Canonic is the canonical form of a graph along with the labeling that produced it.
+//
+// Canonic vertices are labeled 1..Nv (in order) and each edge has Va < Vb, with edges sorted via Edge.Less().
+type Canonic struct {
+	Graph
+
+	// Labeling[i] is the VtxLabel of the source graph that was assigned canonic VtxLabel i+1.
+	Labeling []VtxLabel
+}
+
+// CanonizeGraph builds G using the given canonizer and returns its canonical form.
+func CanonizeGraph(canonizer IGraphCanonizer, G *Graph) (*Canonic, error) {
+	Gin, Gout := NewGraphIO()
+	go G.Export(Gout)
+
+	if err := canonizer.BuildGraph(Gin); err != nil {
+		return nil, err
+	}
+	labeling, err := CanonicLabeling(canonizer)
+	if err != nil {
+		return nil, err
+	}
+
+	return relabel(G, labeling), nil
+}
+
+// relabel returns G relabeled such that labeling[i] becomes VtxLabel i+1.
+func relabel(G *Graph, labeling []VtxLabel) *Canonic {
+	C := &Canonic{
+		Labeling: labeling,
+	}
+	C.Vtx = make([]Vtx, len(labeling))
+	C.Edges = make([]Edge, 0, len(G.Edges))
+
+	canonicOf := make(map[VtxLabel]VtxLabel, len(labeling))
+	for i, vi := range labeling {
+		canonicOf[vi] = VtxLabel(i + 1)
+	}
+	for _, v := range G.Vtx {
+		ci := canonicOf[v.Label]
+		C.Vtx[ci-1] = Vtx{
+			Label: ci,
+			Color: v.Color,
+		}
+	}
+	for _, e := range G.Edges {
+		C.Edges = append(C.Edges, Edge(Edge{
+			Va:    canonicOf[e.Va],
+			Vb:    canonicOf[e.Vb],
+			Color: e.Color,
+		}.FormCanonicalEdge()))
+	}
+	sort.Slice(C.Edges, func(i, j int) bool {
+		return C.Edges[i].Less(C.Edges[j])
+	})
+
+	return C
+}
+
+// Encoding returns the canonical GraphEncoding of C, meaning isomorphic graphs have identical encodings.
+func (C *Canonic) Encoding() GraphEncoding {
+	return C.AppendEncoding(nil)
+}
+
+// AppendEncoding appends C's GraphEncoding to the given buffer.
+//
+// The layout is that of a canonic block (see printBlock): Nv, each VtxColor, Ne, and then each edge as Va, EdgeColor, Vb.
+func (C *Canonic) AppendEncoding(out []byte) []byte {
+	var buf [binary.MaxVarintLen64]byte
+
+	n := binary.PutUvarint(buf[:], uint64(len(C.Vtx)))
+	out = append(out, buf[:n]...)
+	for _, v := range C.Vtx {
+		n = binary.PutVarint(buf[:], int64(v.Color))
+		out = append(out, buf[:n]...)
+	}
+
+	n = binary.PutUvarint(buf[:], uint64(len(C.Edges)))
+	out = append(out, buf[:n]...)
+	for _, e := range C.Edges {
+		n = binary.PutUvarint(buf[:], uint64(e.Va))
+		out = append(out, buf[:n]...)
+		n = binary.PutVarint(buf[:], int64(e.Color))
+		out = append(out, buf[:n]...)
+		n = binary.PutUvarint(buf[:], uint64(e.Vb))
+		out = append(out, buf[:n]...)
+	}
+
+	return out
+}
+
+// DecodeGraph inflates a GraphEncoding produced by Canonic.AppendEncoding().
+func DecodeGraph(Genc GraphEncoding) (*Graph, error) {
+	pos := 0
+	readUint := func(desc string) (uint64, error) {
+		val, n := binary.Uvarint(Genc[pos:])
+		if n <= 0 {
+			return 0, errors.Wrapf(ErrBadEncoding, "error reading %s at offset %v", desc, pos)
+		}
+		pos += n
+		return val, nil
+	}
+	readInt := func(desc string) (int64, error) {
+		val, n := binary.Varint(Genc[pos:])
+		if n <= 0 {
+			return 0, errors.Wrapf(ErrBadEncoding, "error reading %s at offset %v", desc, pos)
+		}
+		pos += n
+		return val, nil
+	}
+
+	Nv, err := readUint("vtx count")
+	if err != nil {
+		return nil, err
+	}
+	if Nv > uint64(len(Genc)) {
+		return nil, errors.Wrapf(ErrBadEncoding, "vtx count %v exceeds encoding length", Nv)
+	}
+
+	G := &Graph{
+		Vtx: make([]Vtx, Nv),
+	}
+	for i := range G.Vtx {
+		color, err := readInt("VtxColor")
+		if err != nil {
+			return nil, err
+		}
+		G.Vtx[i] = Vtx{
+			Label: VtxLabel(i + 1),
+			Color: VtxColor(color),
+		}
+	}
+
+	Ne, err := readUint("edge count")
+	if err != nil {
+		return nil, err
+	}
+	if Ne > uint64(len(Genc)) {
+		return nil, errors.Wrapf(ErrBadEncoding, "edge count %v exceeds encoding length", Ne)
+	}
+
+	G.Edges = make([]Edge, Ne)
+	for i := range G.Edges {
+		Va, err := readUint("Edge.Va")
+		if err != nil {
+			return nil, err
+		}
+		color, err := readInt("EdgeColor")
+		if err != nil {
+			return nil, err
+		}
+		Vb, err := readUint("Edge.Vb")
+		if err != nil {
+			return nil, err
+		}
+		if Va < 1 || Va > Nv || Vb < 1 || Vb > Nv {
+			return nil, errors.Wrapf(ErrBadEncoding, "edge %d-%d references a vertex outside 1..%d", Va, Vb, Nv)
+		}
+		G.Edges[i] = Edge{
+			Va:    VtxLabel(Va),
+			Vb:    VtxLabel(Vb),
+			Color: EdgeColor(color),
+		}
+	}
+
+	if pos != len(Genc) {
+		return nil, errors.Wrapf(ErrBadEncoding, "unexpected data at offset %v", pos)
+	}
+
+	return G, nil
+}
@@ -0,0 +1,91 @@
+package orca
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCanonicString(t *testing.T) {
+	Gin, Gout := NewGraphIO()
+	go genK8(Gout)
+	G := ReadGraph(Gin)
+
+	// Relabel G (in reverse) and add an isolated vertex to both to ensure the labeling covers all components.
+	G2 := &Graph{}
+	for _, v := range G.Vtx {
+		G2.Vtx = append(G2.Vtx, Vtx{Label: 9 - v.Label, Color: v.Color})
+	}
+	for _, e := range G.Edges {
+		G2.Edges = append(G2.Edges, Edge{Va: 9 - e.Va, Vb: 9 - e.Vb, Color: e.Color})
+	}
+	G.Vtx = append(G.Vtx, Vtx{Label: 9, Color: 5})
+	G2.Vtx = append(G2.Vtx, Vtx{Label: 9, Color: 5})
+
+	ctx := NewCanonizer(DefaultCanonizerOpts)
+	C1, err := CanonizeGraph(ctx, G)
+	if err != nil {
+		t.Fatal(err)
+	}
+	C2, err := CanonizeGraph(ctx, G2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(C1.Labeling) != 9 || len(C1.Edges) != len(G.Edges) {
+		t.Fatalf("canonic form is missing vertices or edges: %v", C1.Graph)
+	}
+
+	str := C1.CanonicString()
+	if str != C2.CanonicString() {
+		t.Fatalf("isomorphic graphs gave different canonic strings: %v vs %v", str, C2.CanonicString())
+	}
+
+	Genc, err := ParseCanonicString(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(Genc, C1.Encoding()) {
+		t.Fatal("canonic string did not round trip")
+	}
+	G3, err := DecodeGraph(Genc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(G3.Vtx) != 9 || len(G3.Edges) != len(G.Edges) {
+		t.Fatalf("decoded graph mismatch: %v", G3)
+	}
+
+	// Any single mistyped char must be caught
+	typo := []byte(str)
+	typo[len(typo)/2] = '0' + (typo[len(typo)/2]-'0'+1)%10
+	if _, err = ParseCanonicString(string(typo)); err == nil {
+		t.Fatal("expected checksum error")
+	}
+}
+
+// otherCanonizer implements only IGraphCanonizer, as an outside implementation would.
+type otherCanonizer struct{}
+
+func (otherCanonizer) BuildGraph(Gin GraphIn) error {
+	Gin.Consume(func(v Vtx, e Edge) {})
+	return nil
+}
+
+func (otherCanonizer) Canonize(Gout GraphOut) {
+	Gout.Break()
+}
+
+func TestUnsupportedCanonizer(t *testing.T) {
+	G, err := ParseGraphText("1-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = CanonizeGraph(otherCanonizer{}, G); err != ErrUnsupportedCanonizer {
+		t.Fatalf("expected ErrUnsupportedCanonizer, got %v", err)
+	}
+	if _, err = NeighborhoodHashes(otherCanonizer{}, G, 1); err != ErrUnsupportedCanonizer {
+		t.Fatalf("expected ErrUnsupportedCanonizer, got %v", err)
+	}
+	if err = ApplyEdits(otherCanonizer{}, GraphEdit{Op: EditRemoveEdge, Edge: G.Edges[0]}); err != ErrUnsupportedCanonizer {
+		t.Fatalf("expected ErrUnsupportedCanonizer, got %v", err)
+	}
+}
@@ -0,0 +1,462 @@
+/*
+Package catalog is an embedded, file-backed store of graphs keyed by canonical encoding (see orca.Canonic), where
+each entry carries caller-defined metadata.  Since the key is canonical, a graph can be found from any labeling of
+it, and entries are kept in lexicographic (predictable) order of their encodings.
+
+A catalog is a single append-only log file:
+
+    header      "ORCACAT2"
+    record      [u32 body len][u32 CRC-32C of body len][u32 CRC-32C of body][body]
+    body        [op][uvarint encoding len][encoding][metadata]
+
+Each put or delete appends a record (and by default syncs the file) before it is applied to the in-memory index, so
+an entry is durable once the call returns.  On open, the log is replayed, with later records superseding earlier
+ones.  A last record that is incomplete or fails its checksum (i.e. a write interrupted by a crash) is truncated.
+Any other bad record, including a valid one with an unknown op, fails Open with ErrCorrupt.  Since the body length
+has its own checksum, a damaged length is caught rather than mistaken for a record running past the end of the file.
+Compact rewrites the log with only live entries and atomically replaces the original.
+
+A SubstructIndex (see Catalog.NewSubstructIndex) answers which entries contain a given pattern graph.
+*/
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/3x2theory/go-orca"
+	"github.com/emirpasic/gods/trees/redblacktree"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNotFound = errors.New("graph not found in catalog")
+	ErrBadFile  = errors.New("not a catalog file")
+	ErrCorrupt  = errors.New("corrupt catalog record")
+	ErrClosed   = errors.New("catalog is closed")
+)
+
+const fileHeader = "ORCACAT2"
+
+const recPrefixLen = 12 // [body len][CRC of body len][CRC of body]
+
+const (
+	opPut    byte = 1
+	opDelete byte = 2
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Opts controls how a catalog is opened.
+type Opts struct {
+	// NoSync skips syncing the file after each write, trading durability for speed.  Writes remain atomic, so a
+	// crash loses only the most recent writes.
+	NoSync bool
+
+	// Canonizer is used by Insert and Lookup (default: orca.NewCanonizer(orca.DefaultCanonizerOpts)).
+	Canonizer orca.IGraphCanonizer
+}
+
+// Entry is a single catalog entry.
+type Entry struct {
+	Encoding orca.GraphEncoding
+	Meta     []byte
+}
+
+// entryRef locates an entry's metadata in the log.
+type entryRef struct {
+	offset int64
+	len    int
+}
+
+// Catalog is safe for concurrent use.
+type Catalog struct {
+	mu      sync.RWMutex
+	path    string
+	opts    Opts
+	file    *os.File
+	size    int64
+	index   redblacktree.Tree // maps orca.GraphEncoding => *entryRef
+	canonMu sync.Mutex        // guards opts.Canonizer
+	indexes []*SubstructIndex // kept up to date by apply
+}
+
+func encodingComparator(a, b interface{}) int {
+	return bytes.Compare(a.(orca.GraphEncoding), b.(orca.GraphEncoding))
+}
+
+// Open opens the catalog at the given path, creating it if it doesn't exist.
+func Open(path string, opts Opts) (*Catalog, error) {
+	if opts.Canonizer == nil {
+		opts.Canonizer = orca.NewCanonizer(orca.DefaultCanonizerOpts)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	cat := &Catalog{
+		path: path,
+		opts: opts,
+		file: file,
+	}
+	if err = cat.load(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return cat, nil
+}
+
+// load replays the log into the index, truncating any torn record at its end.  A bad record followed by more data
+// (or one that checksums but can't be applied, such as from a newer version) is an error and the file is left as is.
+func (cat *Catalog) load() error {
+	cat.index = redblacktree.Tree{
+		Comparator: encodingComparator,
+	}
+
+	info, err := cat.file.Stat()
+	if err != nil {
+		return err
+	}
+	// A file shorter than the header was interrupted while being created
+	if info.Size() < int64(len(fileHeader)) {
+		prefix := make([]byte, info.Size())
+		if _, err = cat.file.ReadAt(prefix, 0); err != nil || !bytes.HasPrefix([]byte(fileHeader), prefix) {
+			return errors.Wrap(ErrBadFile, cat.path)
+		}
+		if _, err = cat.file.WriteAt([]byte(fileHeader), 0); err == nil {
+			err = cat.sync()
+		}
+		cat.size = int64(len(fileHeader))
+		return err
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(cat.file, 0, info.Size()))
+	header := make([]byte, len(fileHeader))
+	if _, err = io.ReadFull(r, header); err != nil || string(header) != fileHeader {
+		return errors.Wrap(ErrBadFile, cat.path)
+	}
+
+	offset := int64(len(fileHeader))
+	var prefix [recPrefixLen]byte
+	for {
+		if _, err = io.ReadFull(r, prefix[:]); err != nil {
+			break
+		}
+		if crc32.Checksum(prefix[0:4], crcTable) != binary.LittleEndian.Uint32(prefix[4:]) {
+			if offset+recPrefixLen == info.Size() {
+				break
+			}
+			return errors.Wrapf(ErrCorrupt, "%s: bad record length at offset %d", cat.path, offset)
+		}
+
+		// The length is sound, so a record running past the end of the file was cut short
+		bodyLen := binary.LittleEndian.Uint32(prefix[0:])
+		end := offset + recPrefixLen + int64(bodyLen)
+		if end > info.Size() {
+			break
+		}
+		body := make([]byte, bodyLen)
+		if _, err = io.ReadFull(r, body); err != nil {
+			break
+		}
+		if crc32.Checksum(body, crcTable) != binary.LittleEndian.Uint32(prefix[8:]) {
+			if end == info.Size() {
+				break
+			}
+			return errors.Wrapf(ErrCorrupt, "%s: checksum mismatch at offset %d", cat.path, offset)
+		}
+		if !cat.apply(body, offset+recPrefixLen) {
+			return errors.Wrapf(ErrCorrupt, "%s: unrecognized record at offset %d", cat.path, offset)
+		}
+		offset = end
+	}
+
+	cat.size = offset
+	if offset < info.Size() {
+		if err = cat.file.Truncate(offset); err == nil {
+			err = cat.sync()
+		}
+		return err
+	}
+	return nil
+}
+
+// apply applies a record body (located at the given file offset) to the index, returning false if it is malformed.
+func (cat *Catalog) apply(body []byte, offset int64) bool {
+	if len(body) < 1 {
+		return false
+	}
+	encLen, n := binary.Uvarint(body[1:])
+	if n <= 0 || encLen > uint64(len(body)-1-n) {
+		return false
+	}
+	encStart := 1 + n
+	Genc := orca.GraphEncoding(append([]byte(nil), body[encStart:encStart+int(encLen)]...))
+	metaStart := encStart + int(encLen)
+
+	switch body[0] {
+	case opPut:
+		if _, found := cat.index.Get(Genc); !found {
+			for _, idx := range cat.indexes {
+				idx.add(Genc)
+			}
+		}
+		cat.index.Put(Genc, &entryRef{
+			offset: offset + int64(metaStart),
+			len:    len(body) - metaStart,
+		})
+	case opDelete:
+		cat.index.Remove(Genc)
+		for _, idx := range cat.indexes {
+			idx.remove(Genc)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (cat *Catalog) sync() error {
+	if cat.opts.NoSync {
+		return nil
+	}
+	return cat.file.Sync()
+}
+
+// appendRecord writes a record to the end of the log and applies it to the index.
+func (cat *Catalog) appendRecord(op byte, Genc orca.GraphEncoding, meta []byte) error {
+	if cat.file == nil {
+		return ErrClosed
+	}
+
+	body := make([]byte, 1, 1+binary.MaxVarintLen64+len(Genc)+len(meta))
+	body[0] = op
+	var lenBuf [binary.MaxVarintLen64]byte
+	body = append(body, lenBuf[:binary.PutUvarint(lenBuf[:], uint64(len(Genc)))]...)
+	body = append(body, Genc...)
+	body = append(body, meta...)
+
+	rec := make([]byte, recPrefixLen, recPrefixLen+len(body))
+	binary.LittleEndian.PutUint32(rec[0:], uint32(len(body)))
+	binary.LittleEndian.PutUint32(rec[4:], crc32.Checksum(rec[0:4], crcTable))
+	binary.LittleEndian.PutUint32(rec[8:], crc32.Checksum(body, crcTable))
+	rec = append(rec, body...)
+
+	_, err := cat.file.WriteAt(rec, cat.size)
+	if err == nil {
+		err = cat.sync()
+	}
+	if err != nil {
+		// Drop any partial write so the log stays well formed
+		cat.file.Truncate(cat.size)
+		return err
+	}
+	cat.apply(body, cat.size+recPrefixLen)
+	cat.size += int64(len(rec))
+	return nil
+}
+
+// Close closes the catalog file.
+func (cat *Catalog) Close() error {
+	cat.mu.Lock()
+	defer cat.mu.Unlock()
+	if cat.file == nil {
+		return ErrClosed
+	}
+	err := cat.file.Close()
+	cat.file = nil
+	return err
+}
+
+// Len returns the number of entries.
+func (cat *Catalog) Len() int {
+	cat.mu.RLock()
+	defer cat.mu.RUnlock()
+	return cat.index.Size()
+}
+
+// Put sets the metadata for the given canonical encoding, adding an entry if needed.
+func (cat *Catalog) Put(Genc orca.GraphEncoding, meta []byte) error {
+	cat.mu.Lock()
+	defer cat.mu.Unlock()
+	return cat.appendRecord(opPut, Genc, meta)
+}
+
+// Delete removes the entry for the given canonical encoding, returning ErrNotFound if there is none.
+func (cat *Catalog) Delete(Genc orca.GraphEncoding) error {
+	cat.mu.Lock()
+	defer cat.mu.Unlock()
+	if _, found := cat.index.Get(Genc); !found {
+		return ErrNotFound
+	}
+	return cat.appendRecord(opDelete, Genc, nil)
+}
+
+// Get returns the metadata for the given canonical encoding, returning ErrNotFound if there is no such entry.
+func (cat *Catalog) Get(Genc orca.GraphEncoding) ([]byte, error) {
+	cat.mu.RLock()
+	defer cat.mu.RUnlock()
+	if cat.file == nil {
+		return nil, ErrClosed
+	}
+	ref, found := cat.index.Get(Genc)
+	if !found {
+		return nil, ErrNotFound
+	}
+	return cat.readMeta(ref.(*entryRef))
+}
+
+func (cat *Catalog) readMeta(ref *entryRef) ([]byte, error) {
+	meta := make([]byte, ref.len)
+	_, err := cat.file.ReadAt(meta, ref.offset)
+	return meta, err
+}
+
+// canonize returns the canonical encoding of G.
+func (cat *Catalog) canonize(G *orca.Graph) (orca.GraphEncoding, error) {
+	cat.canonMu.Lock()
+	defer cat.canonMu.Unlock()
+	C, err := orca.CanonizeGraph(cat.opts.Canonizer, G)
+	if err != nil {
+		return nil, err
+	}
+	return C.Encoding(), nil
+}
+
+// Insert adds G (under any labeling) with the given metadata unless it is already present, returning its canonical
+// encoding and whether it was added.
+func (cat *Catalog) Insert(G *orca.Graph, meta []byte) (orca.GraphEncoding, bool, error) {
+	Genc, err := cat.canonize(G)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cat.mu.Lock()
+	defer cat.mu.Unlock()
+	if _, found := cat.index.Get(Genc); found {
+		return Genc, false, nil
+	}
+	if err = cat.appendRecord(opPut, Genc, meta); err != nil {
+		return nil, false, err
+	}
+	return Genc, true, nil
+}
+
+// Lookup finds G (under any labeling), returning its canonical encoding and metadata or ErrNotFound.
+func (cat *Catalog) Lookup(G *orca.Graph) (orca.GraphEncoding, []byte, error) {
+	Genc, err := cat.canonize(G)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta, err := cat.Get(Genc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return Genc, meta, nil
+}
+
+// Scan calls fn for each entry whose encoding is in [from, to), in encoding order, where a nil from or to means
+// unbounded.  The catalog is read-locked during the scan, so fn must not modify it.
+func (cat *Catalog) Scan(from, to orca.GraphEncoding, fn func(entry Entry) error) error {
+	cat.mu.RLock()
+	defer cat.mu.RUnlock()
+	if cat.file == nil {
+		return ErrClosed
+	}
+
+	var node *redblacktree.Node
+	if from == nil {
+		node = cat.index.Left()
+	} else {
+		node, _ = cat.index.Ceiling(from)
+	}
+	for ; node != nil; node = successor(node) {
+		Genc := node.Key.(orca.GraphEncoding)
+		if to != nil && bytes.Compare(Genc, to) >= 0 {
+			break
+		}
+		meta, err := cat.readMeta(node.Value.(*entryRef))
+		if err != nil {
+			return err
+		}
+		if err = fn(Entry{Genc, meta}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// successor returns the next node in key order.
+func successor(node *redblacktree.Node) *redblacktree.Node {
+	if node.Right != nil {
+		node = node.Right
+		for node.Left != nil {
+			node = node.Left
+		}
+		return node
+	}
+	for node.Parent != nil && node == node.Parent.Right {
+		node = node.Parent
+	}
+	return node.Parent
+}
+
+// Compact rewrites the log to contain only live entries, atomically replacing the catalog file.
+func (cat *Catalog) Compact() error {
+	cat.mu.Lock()
+	defer cat.mu.Unlock()
+	if cat.file == nil {
+		return ErrClosed
+	}
+
+	tmpPath := cat.path + ".compact"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	compacted := &Catalog{
+		path: cat.path,
+		opts: cat.opts,
+		file: tmp,
+	}
+	compacted.opts.NoSync = true // synced once below
+	err = compacted.load()
+	for it := cat.index.Iterator(); err == nil && it.Next(); {
+		var meta []byte
+		if meta, err = cat.readMeta(it.Value().(*entryRef)); err == nil {
+			err = compacted.appendRecord(opPut, it.Key().(orca.GraphEncoding), meta)
+		}
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, cat.path)
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	syncDir(filepath.Dir(cat.path))
+
+	cat.file.Close()
+	cat.file = tmp
+	cat.size = compacted.size
+	cat.index = compacted.index
+	return nil
+}
+
+// syncDir makes a rename within dir durable (where the platform supports it).
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,158 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/3x2theory/go-orca"
+	"github.com/pkg/errors"
+)
+
+func parse(t *testing.T, text string) *orca.Graph {
+	G, err := orca.ParseGraphText(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return G
+}
+
+func TestCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.cat")
+	cat, err := Open(path, Opts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pathEnc, added, err := cat.Insert(parse(t, "1-2 2-3 v3:1"), []byte("path"))
+	if err != nil || !added {
+		t.Fatal("insert failed", err)
+	}
+	if _, added, _ = cat.Insert(parse(t, "3-2 2-1 v1:1"), []byte("dup")); added {
+		t.Fatal("expected existing entry")
+	}
+	triEnc, _, _ := cat.Insert(parse(t, "1-2 2-3 3-1"), []byte("triangle"))
+	starEnc, _, _ := cat.Insert(parse(t, "1-2 1-3 1-4"), []byte("star"))
+	if err = cat.Put(triEnc, []byte("K3")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Lookup by another labeling
+	Genc, meta, err := cat.Lookup(parse(t, "2-1 v1:1 3-2"))
+	if err != nil || string(Genc) != string(pathEnc) || string(meta) != "path" {
+		t.Fatalf("unexpected lookup %q %v", meta, err)
+	}
+	if _, _, err = cat.Lookup(parse(t, "1-2")); err != ErrNotFound {
+		t.Fatal("expected ErrNotFound")
+	}
+
+	// Simulate a crash mid-write by appending a torn record, then reopen
+	cat.Close()
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	file.Write([]byte{40, 0, 0, 0, 1, 2, 3, 4, 1, 9})
+	file.Close()
+
+	cat, err = Open(path, Opts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cat.Close()
+	if meta, err = cat.Get(triEnc); err != nil || string(meta) != "K3" || cat.Len() != 3 {
+		t.Fatalf("unexpected reopened catalog: %q %v", meta, err)
+	}
+
+	// Scan everything below the star (4 vertices), in encoding order
+	var scanned []string
+	if err = cat.Scan(nil, starEnc, func(entry Entry) error {
+		scanned = append(scanned, string(entry.Meta))
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"path", "K3"}
+	if string(triEnc) < string(pathEnc) {
+		expected = []string{"K3", "path"}
+	}
+	if len(scanned) != 2 || scanned[0] != expected[0] || scanned[1] != expected[1] {
+		t.Fatalf("unexpected scan %v", scanned)
+	}
+
+	if err = cat.Delete(pathEnc); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.Stat(path)
+	if err = cat.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := os.Stat(path)
+	if after.Size() >= before.Size() || cat.Len() != 2 {
+		t.Fatalf("compaction failed: %d -> %d bytes", before.Size(), after.Size())
+	}
+	if meta, err = cat.Get(starEnc); err != nil || string(meta) != "star" {
+		t.Fatalf("unexpected compacted catalog: %q %v", meta, err)
+	}
+	if _, err = cat.Get(pathEnc); err != ErrNotFound {
+		t.Fatal("expected deleted entry")
+	}
+}
+
+func TestCatalogUnknownRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.cat")
+	cat, err := Open(path, Opts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pathEnc, _, err := cat.Insert(parse(t, "1-2 2-3"), []byte("path"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A record from a newer version (with a valid checksum) followed by an ordinary put
+	if err = cat.appendRecord(0xFF, pathEnc, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = cat.Insert(parse(t, "1-2 2-3 3-1"), []byte("triangle")); err != nil {
+		t.Fatal(err)
+	}
+	cat.Close()
+	before, _ := os.Stat(path)
+
+	if _, err = Open(path, Opts{}); errors.Cause(err) != ErrCorrupt {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+	after, _ := os.Stat(path)
+	if after.Size() != before.Size() {
+		t.Fatalf("expected the file to be left unchanged, size went from %d to %d", before.Size(), after.Size())
+	}
+}
+
+func TestCatalogBadLength(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.cat")
+	cat, err := Open(path, Opts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"1-2", "1-2 2-3", "1-2 2-3 3-1"} {
+		if _, _, err = cat.Insert(parse(t, text), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cat.Close()
+
+	// Point the first record's length past the end of the file, as a flipped bit could
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(fileHeader)+2] ^= 0x40
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = Open(path, Opts{}); errors.Cause(err) != ErrCorrupt {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+	if after, _ := os.ReadFile(path); len(after) != len(data) {
+		t.Fatalf("expected the file to be left unchanged, size went from %d to %d", len(data), len(after))
+	}
+}
@@ -0,0 +1,211 @@
+package catalog
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/3x2theory/go-orca"
+)
+
+// SubstructIndex finds catalog entries containing a pattern graph.  Each entry's graph is kept decoded along with its
+// path fingerprint (see orca.PathFingerprint), so a search screens out entries whose fingerprint lacks a bit of the
+// pattern's and then confirms the rest with exact subgraph matching.
+//
+// An index is held in memory and follows the catalog it was created from as entries are added and deleted.  New
+// entries are only noted as the catalog changes, and are decoded and fingerprinted by the next search (or Len), so the
+// catalog's lock is never held for that work.  An entry with more paths than opts.MaxPaths allows is left unscreened,
+// meaning every search passes it on to exact matching.
+// Entries whose encoding doesn't decode into a graph (e.g. stored with Put rather than Insert) can't be matched, and
+// are listed by Unindexed instead.
+type SubstructIndex struct {
+	cat      *Catalog
+	opts     orca.FingerprintOpts
+	mu       sync.RWMutex
+	entries  map[string]*indexedGraph // keyed by encoding
+	indexing sync.Mutex               // serializes indexPending
+}
+
+type indexedGraph struct {
+	Genc    orca.GraphEncoding
+	indexed bool
+	G       *orca.Graph      // nil if Genc doesn't decode
+	err     error            // why Genc doesn't decode
+	fp      orca.Fingerprint // nil if unscreened
+}
+
+// UnindexedEntry is an entry whose encoding didn't decode into a graph, along with the decoding error.
+type UnindexedEntry struct {
+	Encoding orca.GraphEncoding
+	Err      error
+}
+
+// SubstructMatch is an entry containing a search pattern, along with the embeddings of the pattern in the entry's
+// graph, which maps pattern VtxLabels to the entry's canonic VtxLabels (i.e. of orca.DecodeGraph(Entry.Encoding)).
+type SubstructMatch struct {
+	Entry
+	Embeddings []map[orca.VtxLabel]orca.VtxLabel
+}
+
+// NewSubstructIndex returns an index of the catalog's entries that stays up to date for the catalog's lifetime.
+func (cat *Catalog) NewSubstructIndex(opts orca.FingerprintOpts) (*SubstructIndex, error) {
+	cat.mu.Lock()
+	defer cat.mu.Unlock()
+	if cat.file == nil {
+		return nil, ErrClosed
+	}
+
+	idx := &SubstructIndex{
+		cat:     cat,
+		opts:    opts,
+		entries: make(map[string]*indexedGraph, cat.index.Size()),
+	}
+	for node := cat.index.Left(); node != nil; node = successor(node) {
+		idx.add(node.Key.(orca.GraphEncoding))
+	}
+	cat.indexes = append(cat.indexes, idx)
+	return idx, nil
+}
+
+// add notes the given encoding for indexPending, as it is called with the catalog locked.
+func (idx *SubstructIndex) add(Genc orca.GraphEncoding) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.entries[string(Genc)] = &indexedGraph{Genc: Genc}
+}
+
+func (idx *SubstructIndex) remove(Genc orca.GraphEncoding) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	delete(idx.entries, string(Genc))
+}
+
+// indexPending decodes and fingerprints the entries added since the last call, holding neither lock while it does.
+func (idx *SubstructIndex) indexPending() {
+	idx.indexing.Lock()
+	defer idx.indexing.Unlock()
+
+	idx.mu.RLock()
+	var pending []*indexedGraph
+	for _, entry := range idx.entries {
+		if !entry.indexed {
+			pending = append(pending, entry)
+		}
+	}
+	idx.mu.RUnlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	indexed := make([]*indexedGraph, len(pending))
+	for i, entry := range pending {
+		indexed[i] = &indexedGraph{
+			Genc:    entry.Genc,
+			indexed: true,
+		}
+		G, err := orca.DecodeGraph(entry.Genc)
+		if err != nil {
+			indexed[i].err = err
+			continue
+		}
+		indexed[i].G = G
+		indexed[i].fp, _ = orca.PathFingerprint(G, idx.opts)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for i, entry := range pending {
+		// Skip entries deleted (or deleted and re-added) in the meantime
+		if idx.entries[string(entry.Genc)] == entry {
+			idx.entries[string(entry.Genc)] = indexed[i]
+		}
+	}
+}
+
+// Len returns the number of indexed entries.
+func (idx *SubstructIndex) Len() int {
+	idx.indexPending()
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	count := 0
+	for _, entry := range idx.entries {
+		if entry.G != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// Unindexed returns the entries Search can't match since their encoding doesn't decode, in encoding order.
+func (idx *SubstructIndex) Unindexed() []UnindexedEntry {
+	idx.indexPending()
+	idx.mu.RLock()
+	var unindexed []UnindexedEntry
+	for _, entry := range idx.entries {
+		if entry.err != nil {
+			unindexed = append(unindexed, UnindexedEntry{entry.Genc, entry.err})
+		}
+	}
+	idx.mu.RUnlock()
+	sort.Slice(unindexed, func(i, j int) bool {
+		return bytes.Compare(unindexed[i].Encoding, unindexed[j].Encoding) < 0
+	})
+	return unindexed
+}
+
+// Search calls fn for each entry containing pattern, in encoding order, stopping at the first error fn returns.
+// Matching follows opts (see orca.MatchSubgraphs), where opts.MaxMatches limits the embeddings found per entry.
+// A pattern with more paths than the index's opts.MaxPaths allows can't be screened, so every entry is matched.
+func (idx *SubstructIndex) Search(pattern *orca.Graph, opts orca.MatchOpts, fn func(match SubstructMatch) error) error {
+	patternFp, err := orca.PathFingerprint(pattern, idx.opts)
+	if err != nil && err != orca.ErrFingerprintBudget {
+		return err
+	}
+	idx.indexPending()
+
+	idx.mu.RLock()
+	candidates := make([]*indexedGraph, 0, len(idx.entries))
+	for _, entry := range idx.entries {
+		if entry.G == nil || len(entry.G.Vtx) < len(pattern.Vtx) || len(entry.G.Edges) < len(pattern.Edges) {
+			continue
+		}
+		if patternFp == nil || entry.fp == nil || entry.fp.Contains(patternFp) {
+			candidates = append(candidates, entry)
+		}
+	}
+	idx.mu.RUnlock()
+	sort.Slice(candidates, func(i, j int) bool {
+		return bytes.Compare(candidates[i].Genc, candidates[j].Genc) < 0
+	})
+
+	for _, entry := range candidates {
+		embeddings, err := orca.FindSubgraphs(pattern, entry.G, opts)
+		if err != nil {
+			return err
+		}
+		if len(embeddings) == 0 {
+			continue
+		}
+		meta, err := idx.cat.Get(entry.Genc)
+		if err == ErrNotFound {
+			continue // deleted since the screen
+		}
+		if err != nil {
+			return err
+		}
+		if err = fn(SubstructMatch{Entry{entry.Genc, meta}, embeddings}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindAll returns every entry containing pattern (see Search).
+func (idx *SubstructIndex) FindAll(pattern *orca.Graph, opts orca.MatchOpts) ([]SubstructMatch, error) {
+	var found []SubstructMatch
+	err := idx.Search(pattern, opts, func(match SubstructMatch) error {
+		found = append(found, match)
+		return nil
+	})
+	return found, err
+}
@@ -0,0 +1,131 @@
+package catalog
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/3x2theory/go-orca"
+)
+
+func TestSubstructIndex(t *testing.T) {
+	cat, err := Open(filepath.Join(t.TempDir(), "test.cat"), Opts{NoSync: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cat.Close()
+
+	cat.Insert(parse(t, "1-2 2-3 3-4 4-5 5-6 6-1"), []byte("hexagon"))
+	cat.Insert(parse(t, "1-2 2-3 3-1"), []byte("triangle"))
+	idx, err := cat.NewSubstructIndex(orca.DefaultFingerprintOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Added after the index, so it must follow the catalog
+	starEnc, _, _ := cat.Insert(parse(t, "1-2 1-3 1-4 v4:1"), []byte("star"))
+	if idx.Len() != 3 {
+		t.Fatalf("expected 3 indexed entries, got %d", idx.Len())
+	}
+
+	names := func(pattern string, opts orca.MatchOpts) []string {
+		P := parse(t, pattern)
+		found, err := idx.FindAll(P, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, match := range found {
+			names = append(names, string(match.Meta))
+			G, _ := orca.DecodeGraph(match.Encoding)
+			for _, mapping := range match.Embeddings {
+				for _, e := range P.Edges {
+					if !hasEdge(G, mapping[e.Va], mapping[e.Vb]) {
+						t.Fatalf("embedding in %s doesn't preserve edge %d-%d", match.Meta, e.Va, e.Vb)
+					}
+				}
+			}
+		}
+		return names
+	}
+
+	if found := names("1-2 2-3", orca.MatchOpts{}); len(found) != 3 {
+		t.Fatalf("expected every entry to contain a path, got %v", found)
+	}
+	if found := names("1-2 2-3", orca.MatchOpts{Induced: true}); len(found) != 2 {
+		t.Fatalf("expected an induced path in only the hexagon and star, got %v", found)
+	}
+	if found := names("1-2 2-3 3-1", orca.MatchOpts{}); len(found) != 1 || found[0] != "triangle" {
+		t.Fatalf("expected only the triangle, got %v", found)
+	}
+	if found := names("1-2 v2:1", orca.MatchOpts{MaxMatches: 1}); len(found) != 1 || found[0] != "star" {
+		t.Fatalf("expected only the star, got %v", found)
+	}
+
+	// An entry that doesn't decode is reported rather than silently dropped
+	if err = cat.Put(orca.GraphEncoding("not a graph"), []byte("junk")); err != nil {
+		t.Fatal(err)
+	}
+	if unindexed := idx.Unindexed(); len(unindexed) != 1 || string(unindexed[0].Encoding) != "not a graph" || unindexed[0].Err == nil {
+		t.Fatalf("expected the junk entry to be unindexed, got %v", unindexed)
+	}
+	if idx.Len() != 3 {
+		t.Fatalf("expected 3 indexed entries, got %d", idx.Len())
+	}
+
+	if err = cat.Delete(starEnc); err != nil {
+		t.Fatal(err)
+	}
+	if found := names("1-2 v2:1", orca.MatchOpts{}); len(found) != 0 {
+		t.Fatalf("expected no matches after delete, got %v", found)
+	}
+}
+
+func TestSubstructIndexBudget(t *testing.T) {
+	cat, err := Open(filepath.Join(t.TempDir(), "test.cat"), Opts{NoSync: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cat.Close()
+
+	complete := func(n int) string {
+		var edges []string
+		for i := 1; i <= n; i++ {
+			for j := i + 1; j <= n; j++ {
+				edges = append(edges, fmt.Sprintf("%d-%d", i, j))
+			}
+		}
+		return strings.Join(edges, " ")
+	}
+
+	// K8 has too many paths to fingerprint, and so does K5 as a pattern, but both must still be matched
+	opts := orca.DefaultFingerprintOpts
+	opts.MaxPaths = 200
+	idx, err := cat.NewSubstructIndex(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cat.Insert(parse(t, "1-2 2-3 3-4 4-5 5-6 6-1"), []byte("hexagon"))
+	cat.Insert(parse(t, complete(8)), []byte("K8"))
+	if idx.Len() != 2 {
+		t.Fatalf("expected 2 indexed entries, got %d", idx.Len())
+	}
+	for _, pattern := range []string{"1-2 2-3 3-1", complete(5)} {
+		found, err := idx.FindAll(parse(t, pattern), orca.MatchOpts{MaxMatches: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != 1 || string(found[0].Meta) != "K8" {
+			t.Fatalf("%s: expected only K8, got %v", pattern, found)
+		}
+	}
+}
+
+func hasEdge(G *orca.Graph, va, vb orca.VtxLabel) bool {
+	for _, e := range G.Edges {
+		if (e.Va == va && e.Vb == vb) || (e.Va == vb && e.Vb == va) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,708 @@
+package chem
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/3x2theory/go-orca"
+	"github.com/pkg/errors"
+)
+
+/* MDL molfiles and SDF:
+
+Atoms and bonds map onto the chem color scheme as follows:
+
+  - The atom symbol gives Atom.Element ("D" and "T" are read as hydrogen isotopes 2 and 3).
+  - "M  CHG" / "M  ISO" (V2000) and CHG= / MASS= (V3000) give Atom.Charge and Atom.Isotope.  The V2000 atom block's
+    charge field is honored when no "M  CHG" line is present, while its mass difference field is ignored.
+  - Atom.HCount is the implicit hydrogen count from a simple valence model (see molImplicitHCount).  Where an atom's
+    HCount disagrees with the model, the writer records it in the V2000 hydrogen count field (count+1) or as V3000
+    HCOUNT= (-1 denoting zero), and the reader honors it.
+  - Bond types 1, 2, 3, and 4 map to BondSingle, BondDouble, BondTriple, and BondAromatic.
+
+Coordinates are kept alongside the graph so that they survive a read, canonize, write round trip.
+*/
+
+var ErrBadMolfile = errors.New("bad molfile")
+
+// MolfileFormat selects the connection table version written by WriteMolfile.
+type MolfileFormat int
+
+const (
+	MolfileV2000 MolfileFormat = iota
+	MolfileV3000
+)
+
+// Coord is an atom's position in a molfile.
+type Coord struct {
+	X, Y, Z float64
+}
+
+// DataField is a named SDF data item.
+type DataField struct {
+	Name  string
+	Value string // multi-line values are joined with "\n"
+}
+
+// Molecule is a molfile (or SDF record).
+type Molecule struct {
+	Name    string // header line 1
+	Comment string // header line 3
+	Graph   *orca.Graph
+	Coords  []Coord     // Coords[i] is the position of Graph.Vtx[i]
+	Data    []DataField // SDF data items, in the order read
+}
+
+// Field returns the value of the first SDF data item with the given name.
+func (mol *Molecule) Field(name string) (string, bool) {
+	for _, field := range mol.Data {
+		if field.Name == name {
+			return field.Value, true
+		}
+	}
+	return "", false
+}
+
+// CanonicMolecule returns a copy of mol with its atoms relabeled (and reordered) via the canonic labeling.
+func CanonicMolecule(canonizer orca.IGraphCanonizer, mol *Molecule) (*Molecule, error) {
+	C, err := orca.CanonizeGraph(canonizer, mol.Graph)
+	if err != nil {
+		return nil, err
+	}
+
+	canonic := &Molecule{
+		Name:    mol.Name,
+		Comment: mol.Comment,
+		Graph:   &C.Graph,
+		Data:    mol.Data,
+	}
+	if len(mol.Coords) == len(mol.Graph.Vtx) {
+		coordOf := make(map[orca.VtxLabel]Coord, len(mol.Coords))
+		for i, v := range mol.Graph.Vtx {
+			coordOf[v.Label] = mol.Coords[i]
+		}
+		canonic.Coords = make([]Coord, len(C.Labeling))
+		for i, vi := range C.Labeling {
+			canonic.Coords[i] = coordOf[vi]
+		}
+	}
+	return canonic, nil
+}
+
+// ReadMolfile reads a single molfile (any trailing SDF data items are also read).
+func ReadMolfile(r io.Reader) (*Molecule, error) {
+	mol, err := NewSDFReader(r).Next()
+	if err == io.EOF {
+		err = errors.Wrap(ErrBadMolfile, "empty input")
+	}
+	return mol, err
+}
+
+// SDFReader reads successive records of an SDF stream.
+type SDFReader struct {
+	scanner  *bufio.Scanner
+	lineNum  int
+	lastLine string
+}
+
+func NewSDFReader(r io.Reader) *SDFReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return &SDFReader{
+		scanner: scanner,
+	}
+}
+
+// Next reads the next record, returning io.EOF when there are no more records.
+// After an ErrBadMolfile error, the rest of that record is skipped so the next call reads the record that follows.
+func (R *SDFReader) Next() (*Molecule, error) {
+	mol, err := R.next()
+	if errors.Cause(err) == ErrBadMolfile {
+		R.skipRecord()
+	}
+	return mol, err
+}
+
+// skipRecord reads up to and including the "$$$$" delimiter ending the current record, if not already read.
+func (R *SDFReader) skipRecord() {
+	line := R.lastLine
+	for line != "$$$$" {
+		var err error
+		if line, err = R.readLine(); err != nil {
+			return
+		}
+	}
+}
+
+func (R *SDFReader) next() (*Molecule, error) {
+	// Skip blank lines between records
+	line, err := R.readLine()
+	for err == nil && strings.TrimSpace(line) == "" {
+		line, err = R.readLine()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mol := &Molecule{
+		Name: strings.TrimRight(line, " \r"),
+	}
+	if _, err = R.expectLine(); err != nil {
+		return nil, err
+	}
+	if mol.Comment, err = R.expectLine(); err != nil {
+		return nil, err
+	}
+	mol.Comment = strings.TrimRight(mol.Comment, " \r")
+
+	counts, err := R.expectLine()
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(counts, "V3000") {
+		err = R.readV3000(mol)
+	} else {
+		err = R.readV2000(mol, counts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return mol, R.readData(mol)
+}
+
+func (R *SDFReader) readLine() (string, error) {
+	if !R.scanner.Scan() {
+		if err := R.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	R.lineNum++
+	R.lastLine = strings.TrimRight(R.scanner.Text(), "\r")
+	return R.lastLine, nil
+}
+
+// expectLine reads a line where the end of input is an error.
+func (R *SDFReader) expectLine() (string, error) {
+	line, err := R.readLine()
+	if err == io.EOF {
+		err = R.errorf("unexpected end of input")
+	}
+	return line, err
+}
+
+func (R *SDFReader) errorf(format string, args ...interface{}) error {
+	return errors.Wrapf(ErrBadMolfile, "line %d: %s", R.lineNum, fmt.Sprintf(format, args...))
+}
+
+// atomBuilder accumulates atom traits until the whole connection table has been read.
+type atomBuilder struct {
+	atoms   []smilesAtom
+	hCounts []int // explicit hydrogen count (or -1 if absent)
+	edges   []orca.Edge
+}
+
+func (B *atomBuilder) addAtom(symbol string) error {
+	atom := smilesAtom{}
+	switch symbol {
+	case "D":
+		atom.Element, atom.Isotope = H, 2
+	case "T":
+		atom.Element, atom.Isotope = H, 3
+	default:
+		var found bool
+		if atom.Element, found = ElementForSymbol(symbol); !found {
+			return errors.Errorf("unsupported atom symbol %q", symbol)
+		}
+	}
+	B.atoms = append(B.atoms, atom)
+	B.hCounts = append(B.hCounts, -1)
+	return nil
+}
+
+func (B *atomBuilder) addBond(a, b, bondType int) error {
+	if a < 1 || a > len(B.atoms) || b < 1 || b > len(B.atoms) || a == b {
+		return errors.Errorf("bond %d-%d references an invalid atom", a, b)
+	}
+	var color orca.EdgeColor
+	switch bondType {
+	case 1:
+		color = BondSingle
+	case 2:
+		color = BondDouble
+	case 3:
+		color = BondTriple
+	case 4:
+		color = BondAromatic
+	default:
+		return errors.Errorf("unsupported bond type %d", bondType)
+	}
+	B.edges = append(B.edges, orca.Edge{
+		Va:    orca.VtxLabel(a),
+		Vb:    orca.VtxLabel(b),
+		Color: color,
+	})
+	return nil
+}
+
+func (B *atomBuilder) graph() (*orca.Graph, error) {
+	setBondTraits(B.atoms, B.edges, func(label orca.VtxLabel) int { return int(label) - 1 })
+
+	G := &orca.Graph{
+		Vtx:   make([]orca.Vtx, len(B.atoms)),
+		Edges: B.edges,
+	}
+	for i := range B.atoms {
+		atom := &B.atoms[i]
+		if B.hCounts[i] >= 0 {
+			atom.HCount = B.hCounts[i]
+		} else {
+			atom.HCount = molImplicitHCount(atom)
+		}
+		color, err := atom.VtxColor()
+		if err != nil {
+			return nil, errors.Wrapf(ErrBadMolfile, "atom %d: %v", i+1, err)
+		}
+		G.Vtx[i] = orca.Vtx{
+			Label: orca.VtxLabel(i + 1),
+			Color: color,
+		}
+	}
+	return G, nil
+}
+
+// setBondTraits sets the bond order sum and aromaticity of each atom, where idxOf maps a VtxLabel to an index into atoms[].
+func setBondTraits(atoms []smilesAtom, edges []orca.Edge, idxOf func(orca.VtxLabel) int) {
+	for _, e := range edges {
+		for _, i := range [2]int{idxOf(e.Va), idxOf(e.Vb)} {
+			if e.Color == BondAromatic {
+				_, atoms[i].aromatic = aromaticSymbols[strings.ToLower(atoms[i].Element.Symbol())]
+				atoms[i].bondSum++
+			} else {
+				atoms[i].bondSum += int(e.Color)
+			}
+		}
+	}
+}
+
+// chargedValences gives the valence of common charged atoms (isoelectronic with their neighbor element)
+var chargedValences = map[Atom]int{
+	{Element: C, Charge: 1}:  3,
+	{Element: C, Charge: -1}: 3,
+	{Element: N, Charge: 1}:  4,
+	{Element: N, Charge: -1}: 2,
+	{Element: P, Charge: 1}:  4,
+	{Element: O, Charge: 1}:  3,
+	{Element: O, Charge: -1}: 1,
+	{Element: S, Charge: 1}:  3,
+	{Element: S, Charge: -1}: 1,
+}
+
+// molImplicitHCount returns the implicit hydrogens of an atom: the organic subset valences for neutral atoms,
+// chargedValences for common ions, and otherwise zero.
+func molImplicitHCount(atom *smilesAtom) int {
+	if atom.Charge == 0 {
+		if organicValences[atom.Element] == nil {
+			return 0
+		}
+		return implicitHCount(atom)
+	}
+	valence, found := chargedValences[Atom{Element: atom.Element, Charge: atom.Charge}]
+	bondSum := atom.bondSum
+	if atom.aromatic {
+		bondSum++
+	}
+	if !found || bondSum > valence {
+		return 0
+	}
+	return valence - bondSum
+}
+
+func (R *SDFReader) readV2000(mol *Molecule, counts string) error {
+	Na, err1 := strconv.Atoi(strings.TrimSpace(field(counts, 0, 3)))
+	Nb, err2 := strconv.Atoi(strings.TrimSpace(field(counts, 3, 6)))
+	if err1 != nil || err2 != nil || Na < 0 || Nb < 0 || Na > orca.MaxParsedVtx {
+		return R.errorf("bad counts line")
+	}
+
+	B := atomBuilder{}
+	mol.Coords = make([]Coord, Na)
+	for i := 0; i < Na; i++ {
+		line, err := R.expectLine()
+		if err != nil {
+			return err
+		}
+		mol.Coords[i].X, _ = strconv.ParseFloat(strings.TrimSpace(field(line, 0, 10)), 64)
+		mol.Coords[i].Y, _ = strconv.ParseFloat(strings.TrimSpace(field(line, 10, 20)), 64)
+		mol.Coords[i].Z, _ = strconv.ParseFloat(strings.TrimSpace(field(line, 20, 30)), 64)
+		if err = B.addAtom(strings.TrimSpace(field(line, 31, 34))); err != nil {
+			return R.errorf("%v", err)
+		}
+		if ccc, _ := strconv.Atoi(strings.TrimSpace(field(line, 36, 39))); ccc >= 1 && ccc <= 7 && ccc != 4 {
+			B.atoms[i].Charge = 4 - ccc
+		}
+		if hhh, _ := strconv.Atoi(strings.TrimSpace(field(line, 42, 45))); hhh > 0 {
+			B.hCounts[i] = hhh - 1
+		}
+	}
+
+	for i := 0; i < Nb; i++ {
+		line, err := R.expectLine()
+		if err != nil {
+			return err
+		}
+		a, err1 := strconv.Atoi(strings.TrimSpace(field(line, 0, 3)))
+		b, err2 := strconv.Atoi(strings.TrimSpace(field(line, 3, 6)))
+		bondType, err3 := strconv.Atoi(strings.TrimSpace(field(line, 6, 9)))
+		if err1 != nil || err2 != nil || err3 != nil {
+			return R.errorf("bad bond line")
+		}
+		if err = B.addBond(a, b, bondType); err != nil {
+			return R.errorf("%v", err)
+		}
+	}
+
+	// Properties block
+	chargesReset := false
+	for {
+		line, err := R.expectLine()
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(line, "M  END") {
+			break
+		}
+		isCHG := strings.HasPrefix(line, "M  CHG")
+		if !isCHG && !strings.HasPrefix(line, "M  ISO") {
+			continue
+		}
+		if isCHG && !chargesReset {
+			for i := range B.atoms {
+				B.atoms[i].Charge = 0
+			}
+			chargesReset = true
+		}
+		vals := strings.Fields(line[6:])
+		if len(vals) == 0 {
+			return R.errorf("bad property line")
+		}
+		n, _ := strconv.Atoi(vals[0])
+		if len(vals) < 1+2*n {
+			return R.errorf("bad property line")
+		}
+		for j := 0; j < n; j++ {
+			atomIdx, err1 := strconv.Atoi(vals[1+2*j])
+			val, err2 := strconv.Atoi(vals[2+2*j])
+			if err1 != nil || err2 != nil || atomIdx < 1 || atomIdx > Na {
+				return R.errorf("bad property line")
+			}
+			if isCHG {
+				B.atoms[atomIdx-1].Charge = val
+			} else {
+				B.atoms[atomIdx-1].Isotope = val
+			}
+		}
+	}
+
+	var err error
+	mol.Graph, err = B.graph()
+	return err
+}
+
+// readV3000Line reads a "M  V30" line, joining continuation lines (ending with '-').
+func (R *SDFReader) readV3000Line() (string, error) {
+	var joined strings.Builder
+	for {
+		line, err := R.expectLine()
+		if err != nil {
+			return "", err
+		}
+		if !strings.HasPrefix(line, "M  V30 ") {
+			if strings.HasPrefix(line, "M  END") {
+				return "", R.errorf("unexpected M  END")
+			}
+			return "", R.errorf("expected M  V30 line")
+		}
+		line = strings.TrimRight(line[7:], " ")
+		if strings.HasSuffix(line, "-") {
+			joined.WriteString(line[:len(line)-1])
+			continue
+		}
+		joined.WriteString(line)
+		return joined.String(), nil
+	}
+}
+
+func (R *SDFReader) readV3000(mol *Molecule) error {
+	B := atomBuilder{}
+
+	section := ""
+	idxOf := make(map[int]int) // V3000 atom index => zero-based atom index
+	for done := false; !done; {
+		line, err := R.readV3000Line()
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case fields[0] == "BEGIN" && len(fields) > 1:
+			section = fields[1]
+		case fields[0] == "END" && len(fields) > 1:
+			if fields[1] == "CTAB" {
+				done = true
+			}
+			section = ""
+		case section == "ATOM":
+			if len(fields) < 6 {
+				return R.errorf("bad atom line")
+			}
+			atomIdx, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return R.errorf("bad atom index")
+			}
+			if err = B.addAtom(fields[1]); err != nil {
+				return R.errorf("%v", err)
+			}
+			idxOf[atomIdx] = len(B.atoms)
+			coord := Coord{}
+			coord.X, _ = strconv.ParseFloat(fields[2], 64)
+			coord.Y, _ = strconv.ParseFloat(fields[3], 64)
+			coord.Z, _ = strconv.ParseFloat(fields[4], 64)
+			mol.Coords = append(mol.Coords, coord)
+
+			atom := &B.atoms[len(B.atoms)-1]
+			for _, prop := range fields[6:] {
+				kv := strings.SplitN(prop, "=", 2)
+				if len(kv) != 2 {
+					continue
+				}
+				val, err := strconv.Atoi(kv[1])
+				if err != nil {
+					continue
+				}
+				switch kv[0] {
+				case "CHG":
+					atom.Charge = val
+				case "MASS":
+					atom.Isotope = val
+				case "HCOUNT":
+					if val < 0 {
+						val = 0
+					}
+					B.hCounts[len(B.atoms)-1] = val
+				}
+			}
+		case section == "BOND":
+			if len(fields) < 4 {
+				return R.errorf("bad bond line")
+			}
+			bondType, err1 := strconv.Atoi(fields[1])
+			a, err2 := strconv.Atoi(fields[2])
+			b, err3 := strconv.Atoi(fields[3])
+			if err1 != nil || err2 != nil || err3 != nil {
+				return R.errorf("bad bond line")
+			}
+			if err := B.addBond(idxOf[a], idxOf[b], bondType); err != nil {
+				return R.errorf("%v", err)
+			}
+		}
+	}
+
+	for {
+		line, err := R.expectLine()
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(line, "M  END") {
+			break
+		}
+	}
+
+	var err error
+	mol.Graph, err = B.graph()
+	return err
+}
+
+// readData reads SDF data items up to and including the "$$$$" record delimiter (or the end of input).
+func (R *SDFReader) readData(mol *Molecule) error {
+	var field *DataField
+	var value []string
+	for {
+		line, err := R.readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if line == "$$$$" {
+			break
+		}
+		switch {
+		case strings.HasPrefix(line, ">"):
+			nameL := strings.IndexByte(line, '<')
+			nameR := strings.LastIndexByte(line, '>')
+			if nameL < 0 || nameR < nameL {
+				field = &DataField{}
+			} else {
+				field = &DataField{Name: line[nameL+1 : nameR]}
+			}
+			value = value[:0]
+		case field != nil && line == "":
+			field.Value = strings.Join(value, "\n")
+			mol.Data = append(mol.Data, *field)
+			field = nil
+		case field != nil:
+			value = append(value, line)
+		}
+	}
+	if field != nil {
+		field.Value = strings.Join(value, "\n")
+		mol.Data = append(mol.Data, *field)
+	}
+	return nil
+}
+
+// field returns line[L:R], clipped to the length of the line.
+func field(line string, L, R int) string {
+	if L >= len(line) {
+		return ""
+	}
+	if R > len(line) {
+		R = len(line)
+	}
+	return line[L:R]
+}
+
+// WriteMolfile writes mol as a molfile in the given format, with atoms in the order of mol.Graph.Vtx.
+func WriteMolfile(w io.Writer, mol *Molecule, format MolfileFormat) error {
+	G := mol.Graph
+	Na, Nb := len(G.Vtx), len(G.Edges)
+
+	atoms := make([]smilesAtom, Na)
+	idxOf := make(map[orca.VtxLabel]int, Na)
+	for i, v := range G.Vtx {
+		idxOf[v.Label] = i
+		atoms[i].Atom = AtomForColor(v.Color)
+		if atoms[i].Element.Symbol() == "" {
+			return errors.Wrapf(ErrBadAtom, "vertex %d has VtxColor %d", v.Label, v.Color)
+		}
+	}
+	bondTypes := make([]int, Nb)
+	for i, e := range G.Edges {
+		_, foundA := idxOf[e.Va]
+		_, foundB := idxOf[e.Vb]
+		if !foundA || !foundB {
+			return errors.Errorf("edge %d-%d references a missing vertex", e.Va, e.Vb)
+		}
+		switch e.Color {
+		case BondSingle, BondDouble, BondTriple:
+			bondTypes[i] = int(e.Color)
+		case BondAromatic:
+			bondTypes[i] = 4
+		default:
+			return errors.Errorf("edge %d-%d has a bond color not representable in a molfile (%d)", e.Va, e.Vb, e.Color)
+		}
+	}
+	setBondTraits(atoms, G.Edges, func(label orca.VtxLabel) int { return idxOf[label] })
+
+	coord := func(i int) Coord {
+		if len(mol.Coords) == Na {
+			return mol.Coords[i]
+		}
+		return Coord{}
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "%s\n  go-orca\n%s\n", mol.Name, mol.Comment)
+
+	if format == MolfileV2000 {
+		if Na > 999 || Nb > 999 {
+			return errors.New("molecule too large for V2000 (use V3000)")
+		}
+		fmt.Fprintf(out, "%3d%3d  0  0  0  0  0  0  0  0999 V2000\n", Na, Nb)
+		for i, atom := range atoms {
+			hhh := 0
+			if atom.HCount != molImplicitHCount(&atom) {
+				hhh = atom.HCount + 1
+			}
+			c := coord(i)
+			fmt.Fprintf(out, "%10.4f%10.4f%10.4f %-3s 0  0  0%3d  0  0  0  0  0  0  0  0\n", c.X, c.Y, c.Z, atom.Element.Symbol(), hhh)
+		}
+		for i, e := range G.Edges {
+			fmt.Fprintf(out, "%3d%3d%3d  0\n", idxOf[e.Va]+1, idxOf[e.Vb]+1, bondTypes[i])
+		}
+		writeV2000Props(out, "CHG", atoms, func(atom Atom) int { return atom.Charge })
+		writeV2000Props(out, "ISO", atoms, func(atom Atom) int { return atom.Isotope })
+	} else {
+		fmt.Fprintf(out, "  0  0  0     0  0            999 V3000\n")
+		fmt.Fprintf(out, "M  V30 BEGIN CTAB\nM  V30 COUNTS %d %d 0 0 0\nM  V30 BEGIN ATOM\n", Na, Nb)
+		for i, atom := range atoms {
+			c := coord(i)
+			fmt.Fprintf(out, "M  V30 %d %s %.4f %.4f %.4f 0", i+1, atom.Element.Symbol(), c.X, c.Y, c.Z)
+			if atom.Charge != 0 {
+				fmt.Fprintf(out, " CHG=%d", atom.Charge)
+			}
+			if atom.Isotope != 0 {
+				fmt.Fprintf(out, " MASS=%d", atom.Isotope)
+			}
+			if atom.HCount != molImplicitHCount(&atom) {
+				hCount := atom.HCount
+				if hCount == 0 {
+					hCount = -1
+				}
+				fmt.Fprintf(out, " HCOUNT=%d", hCount)
+			}
+			out.WriteByte('\n')
+		}
+		fmt.Fprintf(out, "M  V30 END ATOM\nM  V30 BEGIN BOND\n")
+		for i, e := range G.Edges {
+			fmt.Fprintf(out, "M  V30 %d %d %d %d\n", i+1, bondTypes[i], idxOf[e.Va]+1, idxOf[e.Vb]+1)
+		}
+		fmt.Fprintf(out, "M  V30 END BOND\nM  V30 END CTAB\n")
+	}
+	out.WriteString("M  END\n")
+	return out.Flush()
+}
+
+// writeV2000Props writes "M  CHG" or "M  ISO" lines (at most 8 entries per line) for atoms having a non-zero value.
+func writeV2000Props(out *bufio.Writer, prop string, atoms []smilesAtom, valueOf func(Atom) int) {
+	var entries [][2]int
+	for i, atom := range atoms {
+		if val := valueOf(atom.Atom); val != 0 {
+			entries = append(entries, [2]int{i + 1, val})
+		}
+	}
+	for len(entries) > 0 {
+		n := len(entries)
+		if n > 8 {
+			n = 8
+		}
+		fmt.Fprintf(out, "M  %s%3d", prop, n)
+		for _, entry := range entries[:n] {
+			fmt.Fprintf(out, " %3d %3d", entry[0], entry[1])
+		}
+		out.WriteByte('\n')
+		entries = entries[n:]
+	}
+}
+
+// WriteSDF writes mol as an SDF record: a molfile, its data items, and the "$$$$" delimiter.
+func WriteSDF(w io.Writer, mol *Molecule, format MolfileFormat) error {
+	if err := WriteMolfile(w, mol, format); err != nil {
+		return err
+	}
+	out := bufio.NewWriter(w)
+	for _, field := range mol.Data {
+		fmt.Fprintf(out, ">  <%s>\n%s\n\n", field.Name, field.Value)
+	}
+	out.WriteString("$$$$\n")
+	return out.Flush()
+}
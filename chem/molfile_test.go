@@ -0,0 +1,140 @@
+package chem
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/3x2theory/go-orca"
+	"github.com/pkg/errors"
+)
+
+const ethanolAmmoniumSDF = `ethanol
+  test
+comment
+  3  2  0  0  0  0  0  0  0  0999 V2000
+    0.0000    0.0000    0.0000 C   0  0  0  0  0  0  0  0  0  0  0  0
+    1.5000    0.0000    0.0000 C   0  0  0  0  0  0  0  0  0  0  0  0
+    2.0000    1.0000    0.0000 O   0  0  0  0  0  0  0  0  0  0  0  0
+  1  2  1  0
+  2  3  1  0
+M  END
+>  <ID>
+E-1
+
+>  <NOTE>
+line one
+line two
+
+$$$$
+ammonium
+  test
+
+  1  0  0  0  0  0  0  0  0  0999 V2000
+    0.0000    0.0000    0.0000 N   0  0  0  0  0  0  0  0  0  0  0  0
+M  CHG  1   1   1
+M  END
+$$$$
+`
+
+func TestSDF(t *testing.T) {
+	R := NewSDFReader(strings.NewReader(ethanolAmmoniumSDF))
+	var mols []*Molecule
+	for {
+		mol, err := R.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		mols = append(mols, mol)
+	}
+	if len(mols) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(mols))
+	}
+
+	ethanol, _ := ParseSMILES("CCO")
+	if !sameColors(mols[0].Graph, ethanol) {
+		t.Fatalf("ethanol colors mismatch: %v vs %v", mols[0].Graph, ethanol)
+	}
+	if note, _ := mols[0].Field("NOTE"); note != "line one\nline two" {
+		t.Fatalf("unexpected NOTE field %q", note)
+	}
+	if atom := AtomForColor(mols[1].Graph.Vtx[0].Color); atom != (Atom{Element: N, Charge: 1, HCount: 4}) {
+		t.Fatalf("unexpected ammonium atom %+v", atom)
+	}
+
+	// Canonic output must read back identically in both formats
+	canonizer := orca.NewCanonizer(CanonizerOpts())
+	for _, format := range []MolfileFormat{MolfileV2000, MolfileV3000} {
+		for _, smiles := range []string{"OC(=O)c1ccc[nH]1", "[13CH3][O-].[Na+]"} {
+			G, _ := ParseSMILES(smiles)
+			mol, err := CanonicMolecule(canonizer, &Molecule{Name: smiles, Graph: G, Data: []DataField{{"SMILES", smiles}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := &bytes.Buffer{}
+			if err = WriteSDF(buf, mol, format); err != nil {
+				t.Fatal(err)
+			}
+			mol2, err := ReadMolfile(buf)
+			if err != nil {
+				t.Fatalf("%s: %v\n%s", smiles, err, buf.String())
+			}
+			if !sameColors(mol.Graph, mol2.Graph) || len(mol2.Graph.Edges) != len(G.Edges) {
+				t.Fatalf("%s: round trip mismatch: %v vs %v", smiles, mol.Graph, mol2.Graph)
+			}
+			if field, _ := mol2.Field("SMILES"); field != smiles {
+				t.Fatalf("%s: data item lost", smiles)
+			}
+		}
+	}
+}
+
+func TestMolfileBadCounts(t *testing.T) {
+	for _, counts := range []string{" -1  0", "  0 -1", "abc  0"} {
+		molfile := "bad\n  test\n\n" + counts + "  0  0  0  0  0  0  0  0999 V2000\nM  END\n"
+		if _, err := ReadMolfile(strings.NewReader(molfile)); err == nil || !strings.Contains(err.Error(), "bad counts line") {
+			t.Fatalf("%q: expected a bad counts line error, got %v", counts, err)
+		}
+	}
+}
+
+func TestSDFBadRecord(t *testing.T) {
+	// A bad record is reported and skipped, leaving the records around it readable
+	bad := "bad\n  test\n\n  1  0  0  0  0  0  0  0  0  0999 V2000\n    0.0000    0.0000    0.0000 Xx  0  0\nM  END\n$$$$\n"
+	R := NewSDFReader(strings.NewReader(bad + ethanolAmmoniumSDF + bad))
+	var names []string
+	numBad := 0
+	for {
+		mol, err := R.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Cause(err) == ErrBadMolfile {
+			numBad++
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, mol.Name)
+	}
+	if numBad != 2 || strings.Join(names, " ") != "ethanol ammonium" {
+		t.Fatalf("expected 2 bad records around ethanol and ammonium, got %d and %v", numBad, names)
+	}
+}
+
+func sameColors(G1, G2 *orca.Graph) bool {
+	if len(G1.Vtx) != len(G2.Vtx) {
+		return false
+	}
+	for i := range G1.Vtx {
+		if G1.Vtx[i].Color != G2.Vtx[i].Color {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,189 @@
+// Package chem is a chemistry preset for go-orca, assigning every element a VtxColor and each bond type an EdgeColor.
+//
+// # Atom colors
+//
+// An atom's VtxColor packs the atom's traits into bit fields:
+//
+//	bits  0..7   atomic number (1..118)
+//	bits  8..17  isotope mass number (0 denotes natural abundance)
+//	bits 18..23  formal charge, zigzag encoded (0, -1, +1, -2, +2, ... => 0, 1, 2, 3, 4, ...)
+//	bits 24..27  count of attached hydrogens that are not themselves vertices
+//
+// So a neutral atom of natural isotope and without implicit hydrogens has a VtxColor equal to its atomic number,
+// making element colors stable and recognizable.  Colors always remain < 2^31.
+//
+// # Bond colors
+//
+// Bonds are EdgeColors 1 (single), 2 (double), 3 (triple), 4 (quadruple), and 5 (aromatic).
+package chem
+
+import (
+	"strings"
+
+	"github.com/3x2theory/go-orca"
+	"github.com/pkg/errors"
+)
+
+// Element is an atomic number.
+type Element uint8
+
+// NumElements is the number of elements in the preset (hydrogen through oganesson).
+const NumElements = 118
+
+// Commonly used elements
+const (
+	H  Element = 1
+	B  Element = 5
+	C  Element = 6
+	N  Element = 7
+	O  Element = 8
+	F  Element = 9
+	P  Element = 15
+	S  Element = 16
+	Cl Element = 17
+	Br Element = 35
+	I  Element = 53
+)
+
+// Symbol returns the element's symbol (e.g. "Cl"), or "" if invalid.
+func (e Element) Symbol() string {
+	if e < 1 || e > NumElements {
+		return ""
+	}
+	return elements[e].symbol
+}
+
+// Name returns the element's English name (e.g. "Chlorine"), or "" if invalid.
+func (e Element) Name() string {
+	if e < 1 || e > NumElements {
+		return ""
+	}
+	return elements[e].name
+}
+
+// ElementForSymbol returns the element having the given symbol (case-sensitive).
+func ElementForSymbol(symbol string) (Element, bool) {
+	e, found := elementBySymbol[symbol]
+	return e, found
+}
+
+var elementBySymbol = func() map[string]Element {
+	bySymbol := make(map[string]Element, NumElements)
+	for e := Element(1); e <= NumElements; e++ {
+		bySymbol[elements[e].symbol] = e
+	}
+	return bySymbol
+}()
+
+// Bond types as EdgeColors
+const (
+	BondSingle    = orca.EdgeColor(1)
+	BondDouble    = orca.EdgeColor(2)
+	BondTriple    = orca.EdgeColor(3)
+	BondQuadruple = orca.EdgeColor(4)
+	BondAromatic  = orca.EdgeColor(5)
+)
+
+// Field layout of an atom VtxColor (see package docs)
+const (
+	isotopeShift = 8
+	chargeShift  = 18
+	hCountShift  = 24
+
+	MaxIsotope = 1<<(chargeShift-isotopeShift) - 1
+	MaxCharge  = 1<<(hCountShift-chargeShift)/2 - 1
+	MaxHCount  = 1<<4 - 1
+)
+
+var ErrBadAtom = errors.New("atom traits out of range")
+
+// Atom holds the traits of an atom that are folded into its VtxColor.
+type Atom struct {
+	Element Element
+	Isotope int // mass number, or 0 for natural abundance
+	Charge  int // formal charge
+	HCount  int // attached hydrogens not present as vertices
+}
+
+// VtxColor returns the color for this atom, per the package docs convention.
+func (a Atom) VtxColor() (orca.VtxColor, error) {
+	switch {
+	case a.Element < 1 || a.Element > NumElements:
+		return 0, errors.Wrapf(ErrBadAtom, "invalid atomic number %d", a.Element)
+	case a.Isotope < 0 || a.Isotope > MaxIsotope:
+		return 0, errors.Wrapf(ErrBadAtom, "isotope %d", a.Isotope)
+	case a.Charge < -MaxCharge || a.Charge > MaxCharge:
+		return 0, errors.Wrapf(ErrBadAtom, "charge %d", a.Charge)
+	case a.HCount < 0 || a.HCount > MaxHCount:
+		return 0, errors.Wrapf(ErrBadAtom, "hydrogen count %d", a.HCount)
+	}
+
+	charge := a.Charge << 1
+	if a.Charge < 0 {
+		charge = -charge - 1
+	}
+	return orca.VtxColor(int64(a.Element) |
+		int64(a.Isotope)<<isotopeShift |
+		int64(charge)<<chargeShift |
+		int64(a.HCount)<<hCountShift), nil
+}
+
+// AtomForColor is the inverse of Atom.VtxColor()
+func AtomForColor(color orca.VtxColor) Atom {
+	charge := int(color>>chargeShift) & (1<<(hCountShift-chargeShift) - 1)
+	if charge&1 != 0 {
+		charge = -(charge + 1) >> 1
+	} else {
+		charge >>= 1
+	}
+	return Atom{
+		Element: Element(color & 0xFF),
+		Isotope: int(color>>isotopeShift) & MaxIsotope,
+		Charge:  charge,
+		HCount:  int(color>>hCountShift) & MaxHCount,
+	}
+}
+
+// VtxColorRegistry returns a new registry holding every element, where each element's VtxColor is its atomic number.
+// Each def is named by its symbol and has the element's lowercase name as an alias (e.g. "Cl" and "chlorine").
+func VtxColorRegistry() *orca.VtxColorRegistry {
+	defs := make([]orca.VtxColorDef, NumElements)
+	for e := Element(1); e <= NumElements; e++ {
+		defs[e-1] = orca.VtxColorDef{
+			NameAscii: e.Symbol(),
+			Aliases:   []string{strings.ToLower(e.Name())},
+			Desc:      e.Name(),
+			VtxColor:  int64(e),
+		}
+	}
+
+	R := orca.NewVtxColorRegistry()
+	if err := R.AddVtxColors(defs); err != nil {
+		panic(err)
+	}
+	return R
+}
+
+// EdgeColorRegistry returns a new registry holding each bond type, aliased by its SMILES bond symbol.
+func EdgeColorRegistry() *orca.EdgeColorRegistry {
+	R := orca.NewEdgeColorRegistry()
+	err := R.AddEdgeColors([]orca.EdgeColorDef{
+		{NameAscii: "single", Aliases: []string{"-"}, Desc: "single bond", EdgeColor: int64(BondSingle)},
+		{NameAscii: "double", Aliases: []string{"="}, Desc: "double bond", EdgeColor: int64(BondDouble)},
+		{NameAscii: "triple", Aliases: []string{"#"}, Desc: "triple bond", EdgeColor: int64(BondTriple)},
+		{NameAscii: "quadruple", Aliases: []string{"$"}, Desc: "quadruple bond", EdgeColor: int64(BondQuadruple)},
+		{NameAscii: "aromatic", Aliases: []string{":"}, Desc: "aromatic bond", EdgeColor: int64(BondAromatic)},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return R
+}
+
+// CanonizerOpts returns orca.DefaultCanonizerOpts with the chemistry registries attached.
+func CanonizerOpts() orca.CanonizerOpts {
+	opts := orca.DefaultCanonizerOpts
+	opts.VtxColors = VtxColorRegistry()
+	opts.EdgeColors = EdgeColorRegistry()
+	return opts
+}
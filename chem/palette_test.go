@@ -0,0 +1,44 @@
+package chem
+
+import (
+	"testing"
+)
+
+func TestAtomColors(t *testing.T) {
+	if color, _ := (Atom{Element: C}).VtxColor(); color != 6 {
+		t.Fatalf("plain carbon should have VtxColor 6, got %v", color)
+	}
+
+	for _, atom := range []Atom{
+		{Element: C, Isotope: 13},
+		{Element: N, Charge: 1, HCount: 4},
+		{Element: O, Charge: -2},
+		{Element: 118, Isotope: MaxIsotope, Charge: -MaxCharge, HCount: MaxHCount},
+	} {
+		color, err := atom.VtxColor()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if color >= 1<<31 {
+			t.Fatalf("color %v out of range", color)
+		}
+		if back := AtomForColor(color); back != atom {
+			t.Fatalf("expected %+v, got %+v", atom, back)
+		}
+	}
+
+	if _, err := (Atom{Element: C, Charge: MaxCharge + 1}).VtxColor(); err == nil {
+		t.Fatal("expected ErrBadAtom")
+	}
+
+	R := VtxColorRegistry()
+	if color, _ := R.Resolve("chlorine"); color != 17 {
+		t.Fatalf("expected 17, got %v", color)
+	}
+	if R.Name(26) != "Fe" {
+		t.Fatalf("expected Fe, got %q", R.Name(26))
+	}
+	if color, _ := EdgeColorRegistry().Resolve("#"); color != BondTriple {
+		t.Fatalf("expected BondTriple, got %v", color)
+	}
+}
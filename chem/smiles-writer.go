@@ -0,0 +1,249 @@
+package chem
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/3x2theory/go-orca"
+	"github.com/pkg/errors"
+)
+
+/* SMILES writing:
+
+WriteSMILES traverses a graph depth-first, always starting each component at its lowest VtxLabel and always visiting
+neighbors in VtxLabel order.  Ring closures are opened at the earlier visited atom (along with any bond symbol) and
+are assigned the lowest free ring digit, so the output depends only on the labeling.  Given a canonic labeling (see
+orca.CanonizeGraph), isomorphic molecules therefore produce identical strings.
+*/
+
+// CanonicSMILES canonizes G (a graph in the chem color scheme) and returns its canonical SMILES.
+func CanonicSMILES(canonizer orca.IGraphCanonizer, G *orca.Graph) (string, error) {
+	C, err := orca.CanonizeGraph(canonizer, G)
+	if err != nil {
+		return "", err
+	}
+	return WriteSMILES(&C.Graph)
+}
+
+type smilesNeighbor struct {
+	to   int
+	bond orca.EdgeColor
+}
+
+type smilesWriter struct {
+	out       strings.Builder
+	atoms     []smilesAtom
+	neighbors [][]smilesNeighbor // sorted by VtxLabel
+	order     []int              // DFS visit order of each atom (or -1)
+	parent    []int
+	ringsAt   [][]smilesNeighbor // ring bonds of each atom, sorted by visit order of the other atom
+	ringDigit map[[2]int]int     // digit assigned to an open ring bond
+	digits    []bool             // digits in use
+}
+
+// WriteSMILES returns a SMILES for G (a graph in the chem color scheme) where atoms are written in VtxLabel order.
+func WriteSMILES(G *orca.Graph) (string, error) {
+	vtx := append([]orca.Vtx(nil), G.Vtx...)
+	sort.Slice(vtx, func(i, j int) bool {
+		return vtx[i].Label < vtx[j].Label
+	})
+
+	w := &smilesWriter{
+		atoms:     make([]smilesAtom, len(vtx)),
+		neighbors: make([][]smilesNeighbor, len(vtx)),
+		order:     make([]int, len(vtx)),
+		parent:    make([]int, len(vtx)),
+		ringsAt:   make([][]smilesNeighbor, len(vtx)),
+		ringDigit: make(map[[2]int]int),
+		digits:    make([]bool, 1), // ring digit 0 is not used
+	}
+
+	idxOf := make(map[orca.VtxLabel]int, len(vtx))
+	for i, v := range vtx {
+		idxOf[v.Label] = i
+		w.atoms[i].Atom = AtomForColor(v.Color)
+		if w.atoms[i].Element.Symbol() == "" {
+			return "", errors.Wrapf(ErrBadAtom, "vertex %d has VtxColor %d", v.Label, v.Color)
+		}
+		w.order[i] = -1
+	}
+
+	for _, e := range G.Edges {
+		a, foundA := idxOf[e.Va]
+		b, foundB := idxOf[e.Vb]
+		if !foundA || !foundB {
+			return "", errors.Errorf("edge %d-%d references a missing vertex", e.Va, e.Vb)
+		}
+		if e.Color < BondSingle || e.Color > BondAromatic {
+			return "", errors.Errorf("edge %d-%d has unknown bond color %d", e.Va, e.Vb, e.Color)
+		}
+		w.neighbors[a] = append(w.neighbors[a], smilesNeighbor{b, e.Color})
+		w.neighbors[b] = append(w.neighbors[b], smilesNeighbor{a, e.Color})
+	}
+
+	for i := range w.atoms {
+		sort.Slice(w.neighbors[i], func(j, k int) bool {
+			return w.neighbors[i][j].to < w.neighbors[i][k].to
+		})
+	}
+	setBondTraits(w.atoms, G.Edges, func(label orca.VtxLabel) int { return idxOf[label] })
+
+	visited := 0
+	for i := range w.atoms {
+		if w.order[i] < 0 {
+			w.parent[i] = -1
+			w.visit(i, &visited)
+		}
+	}
+	for i := range w.ringsAt {
+		rings := w.ringsAt[i]
+		sort.Slice(rings, func(j, k int) bool {
+			return w.order[rings[j].to] < w.order[rings[k].to]
+		})
+	}
+
+	for i := range w.atoms {
+		if w.parent[i] < 0 {
+			if i > 0 {
+				w.out.WriteByte('.')
+			}
+			w.write(i)
+		}
+	}
+	return w.out.String(), nil
+}
+
+// visit assigns DFS visit order and finds ring bonds (edges to an already visited atom other than the parent).
+func (w *smilesWriter) visit(i int, visited *int) {
+	w.order[i] = *visited
+	*visited++
+	for _, nb := range w.neighbors[i] {
+		if w.order[nb.to] < 0 {
+			w.parent[nb.to] = i
+			w.visit(nb.to, visited)
+		} else if nb.to != w.parent[i] && w.order[nb.to] < w.order[i] {
+			w.ringsAt[i] = append(w.ringsAt[i], smilesNeighbor{nb.to, nb.bond})
+			w.ringsAt[nb.to] = append(w.ringsAt[nb.to], smilesNeighbor{i, nb.bond})
+		}
+	}
+}
+
+func (w *smilesWriter) write(i int) {
+	w.writeAtom(i)
+
+	for _, ring := range w.ringsAt[i] {
+		key := [2]int{min(i, ring.to), max(i, ring.to)}
+		if digit, open := w.ringDigit[key]; open {
+			delete(w.ringDigit, key)
+			w.digits[digit] = false
+			w.writeRingDigit(digit)
+		} else {
+			w.writeBond(i, ring.to, ring.bond)
+			digit = w.allocRingDigit()
+			w.ringDigit[key] = digit
+			w.writeRingDigit(digit)
+		}
+	}
+
+	var children []smilesNeighbor
+	for _, nb := range w.neighbors[i] {
+		if w.parent[nb.to] == i {
+			children = append(children, nb)
+		}
+	}
+	for ci, child := range children {
+		branch := ci < len(children)-1
+		if branch {
+			w.out.WriteByte('(')
+		}
+		w.writeBond(i, child.to, child.bond)
+		w.write(child.to)
+		if branch {
+			w.out.WriteByte(')')
+		}
+	}
+}
+
+func (w *smilesWriter) allocRingDigit() int {
+	for digit := 1; digit < len(w.digits); digit++ {
+		if !w.digits[digit] {
+			w.digits[digit] = true
+			return digit
+		}
+	}
+	w.digits = append(w.digits, true)
+	return len(w.digits) - 1
+}
+
+func (w *smilesWriter) writeRingDigit(digit int) {
+	if digit > 9 {
+		w.out.WriteByte('%')
+	}
+	w.out.WriteString(strconv.Itoa(digit))
+}
+
+// writeBond writes the bond symbol between atoms a and b, omitting it where SMILES implies it.
+func (w *smilesWriter) writeBond(a, b int, bond orca.EdgeColor) {
+	bothAromatic := w.atoms[a].aromatic && w.atoms[b].aromatic
+	switch bond {
+	case BondSingle:
+		if bothAromatic {
+			w.out.WriteByte('-')
+		}
+	case BondDouble:
+		w.out.WriteByte('=')
+	case BondTriple:
+		w.out.WriteByte('#')
+	case BondQuadruple:
+		w.out.WriteByte('$')
+	case BondAromatic:
+		if !bothAromatic {
+			w.out.WriteByte(':')
+		}
+	}
+}
+
+// writeAtom writes an organic subset atom where possible and a bracket atom otherwise.
+func (w *smilesWriter) writeAtom(i int) {
+	atom := &w.atoms[i]
+	symbol := atom.Element.Symbol()
+	if atom.aromatic {
+		symbol = strings.ToLower(symbol)
+	}
+
+	if organicValences[atom.Element] != nil && atom.Isotope == 0 && atom.Charge == 0 && atom.HCount == implicitHCount(atom) {
+		w.out.WriteString(symbol)
+		return
+	}
+
+	w.out.WriteByte('[')
+	if atom.Isotope > 0 {
+		w.out.WriteString(strconv.Itoa(atom.Isotope))
+	}
+	w.out.WriteString(symbol)
+	if atom.HCount > 0 {
+		w.out.WriteByte('H')
+		if atom.HCount > 1 {
+			w.out.WriteString(strconv.Itoa(atom.HCount))
+		}
+	}
+	if atom.Charge != 0 {
+		if atom.Charge > 0 {
+			w.out.WriteByte('+')
+		} else {
+			w.out.WriteByte('-')
+		}
+		if abs := max(atom.Charge, -atom.Charge); abs > 1 {
+			w.out.WriteString(strconv.Itoa(abs))
+		}
+	}
+	w.out.WriteByte(']')
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
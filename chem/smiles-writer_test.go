@@ -0,0 +1,54 @@
+package chem
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/3x2theory/go-orca"
+)
+
+func TestCanonicSMILES(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	canonizer := orca.NewCanonizer(CanonizerOpts())
+
+	for _, smiles := range []string{
+		"CCO",
+		"OC(=O)c1ccccc1",
+		"C[NH3+].[Cl-]",
+		"[13CH3]C#N",
+		"C1CC1C(Br)=O",
+	} {
+		G, err := ParseSMILES(smiles)
+		if err != nil {
+			t.Fatal(err)
+		}
+		canonic, err := CanonicSMILES(canonizer, G)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Any relabeling must produce the same SMILES
+		for trial := 0; trial < 5; trial++ {
+			perm := r.Perm(len(G.Vtx))
+			G2 := &orca.Graph{}
+			for _, v := range G.Vtx {
+				G2.Vtx = append(G2.Vtx, orca.Vtx{Label: orca.VtxLabel(perm[v.Label-1] + 1), Color: v.Color})
+			}
+			for _, e := range G.Edges {
+				G2.Edges = append(G2.Edges, orca.Edge{Va: orca.VtxLabel(perm[e.Va-1] + 1), Vb: orca.VtxLabel(perm[e.Vb-1] + 1), Color: e.Color})
+			}
+			if smiles2, _ := CanonicSMILES(canonizer, G2); smiles2 != canonic {
+				t.Fatalf("%s: relabeling gave %q, expected %q", smiles, smiles2, canonic)
+			}
+		}
+
+		// The canonical SMILES must read back as the same molecule
+		G3, err := ParseSMILES(canonic)
+		if err != nil {
+			t.Fatalf("%s: canonical SMILES %q failed to parse: %v", smiles, canonic, err)
+		}
+		if smiles3, _ := CanonicSMILES(canonizer, G3); smiles3 != canonic {
+			t.Fatalf("%s: round trip gave %q, expected %q", smiles, smiles3, canonic)
+		}
+	}
+}
@@ -0,0 +1,443 @@
+package chem
+
+import (
+	"strconv"
+
+	"github.com/3x2theory/go-orca"
+	"github.com/pkg/errors"
+)
+
+/* SMILES reading:
+
+Each heavy atom (and each hydrogen written as its own bracket atom, e.g. "[H]" or "[2H]") becomes a vertex, labeled in
+order of appearance starting from 1, and colored via Atom.VtxColor().  Hydrogens implied by the organic subset and
+hydrogens given by a bracket atom's H count are folded into Atom.HCount rather than becoming vertices.
+
+Bonds become edges colored BondSingle, BondDouble, BondTriple, BondQuadruple, or BondAromatic.  An unwritten bond is
+aromatic when both its atoms are aromatic (lowercase) and single otherwise.  Directional bonds ('/' and '\'),
+chirality, and atom classes are accepted but ignored since they are not (yet) part of the color scheme.
+*/
+
+var ErrBadSMILES = errors.New("bad SMILES")
+
+// organicValences lists the normal valences of the organic subset used to infer implicit hydrogens.
+var organicValences = map[Element][]int{
+	B:  {3},
+	C:  {4},
+	N:  {3, 5},
+	O:  {2},
+	P:  {3, 5},
+	S:  {2, 4, 6},
+	F:  {1},
+	Cl: {1},
+	Br: {1},
+	I:  {1},
+}
+
+// aromaticSymbols maps each lowercase symbol allowed for aromatic atoms to its element
+var aromaticSymbols = map[string]Element{
+	"b":  B,
+	"c":  C,
+	"n":  N,
+	"o":  O,
+	"p":  P,
+	"s":  S,
+	"se": 34,
+	"as": 33,
+	"te": 52,
+}
+
+// ParseSMILES parses the given SMILES into a graph, per the color scheme described above.
+func ParseSMILES(smiles string) (*orca.Graph, error) {
+	p := smilesParser{
+		str:   smiles,
+		rings: make(map[int]ringBond),
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.graph()
+}
+
+// ExportSMILES parses the given SMILES and sends the resulting graph to Gout.
+// The end of the graph is signaled on Gout even if an error is returned.
+func ExportSMILES(smiles string, Gout orca.GraphOut) error {
+	G, err := ParseSMILES(smiles)
+	if err != nil {
+		Gout.Break()
+		return err
+	}
+	G.Export(Gout)
+	return nil
+}
+
+type smilesAtom struct {
+	Atom
+	aromatic bool
+	organic  bool // true if written outside brackets (so hydrogens are implicit)
+	bondSum  int  // sum of bond orders (aromatic bonds count as 1)
+}
+
+type ringBond struct {
+	atom  int
+	color orca.EdgeColor // 0 if unspecified
+	pos   int
+}
+
+type smilesParser struct {
+	str   string
+	pos   int
+	atoms []smilesAtom
+	edges []orca.Edge
+	rings map[int]ringBond
+}
+
+func (p *smilesParser) errorf(format string, args ...interface{}) error {
+	return errors.Wrapf(ErrBadSMILES, "%s at offset %d in %q", errors.Errorf(format, args...), p.pos, p.str)
+}
+
+func (p *smilesParser) parse() error {
+	var branches []int
+	prev := -1                // index of the atom that the next atom bonds to (or -1)
+	bond := orca.EdgeColor(0) // bond symbol preceding the next atom (0 if unwritten)
+
+	for p.pos < len(p.str) {
+		ch := p.str[p.pos]
+		switch {
+
+		case ch == '(':
+			if prev < 0 {
+				return p.errorf("branch without a preceding atom")
+			}
+			branches = append(branches, prev)
+			p.pos++
+
+		case ch == ')':
+			if len(branches) == 0 {
+				return p.errorf("unbalanced ')'")
+			}
+			if bond != 0 {
+				return p.errorf("bond symbol without a following atom")
+			}
+			prev = branches[len(branches)-1]
+			branches = branches[:len(branches)-1]
+			p.pos++
+
+		case ch == '.':
+			if bond != 0 {
+				return p.errorf("bond symbol without a following atom")
+			}
+			prev = -1
+			p.pos++
+
+		case ch == '-' || ch == '/' || ch == '\\':
+			bond = p.setBond(bond, BondSingle)
+		case ch == '=':
+			bond = p.setBond(bond, BondDouble)
+		case ch == '#':
+			bond = p.setBond(bond, BondTriple)
+		case ch == '$':
+			bond = p.setBond(bond, BondQuadruple)
+		case ch == ':':
+			bond = p.setBond(bond, BondAromatic)
+
+		case ch == '%' || (ch >= '0' && ch <= '9'):
+			if prev < 0 {
+				return p.errorf("ring closure without a preceding atom")
+			}
+			if err := p.ringClosure(prev, bond); err != nil {
+				return err
+			}
+			bond = 0
+
+		default:
+			atomIdx, err := p.parseAtom()
+			if err != nil {
+				return err
+			}
+			if prev >= 0 {
+				if err = p.addBond(prev, atomIdx, bond); err != nil {
+					return err
+				}
+			} else if bond != 0 {
+				return p.errorf("bond symbol without a preceding atom")
+			}
+			prev = atomIdx
+			bond = 0
+		}
+		if bond < 0 {
+			return p.errorf("multiple bond symbols")
+		}
+	}
+
+	switch {
+	case len(branches) > 0:
+		return p.errorf("unbalanced '('")
+	case bond != 0:
+		return p.errorf("bond symbol without a following atom")
+	case len(p.rings) > 0:
+		for digit, ring := range p.rings {
+			p.pos = ring.pos
+			return p.errorf("unclosed ring %d", digit)
+		}
+	}
+	return nil
+}
+
+// setBond consumes a bond symbol, returning -1 if a bond symbol was already given.
+func (p *smilesParser) setBond(cur, bond orca.EdgeColor) orca.EdgeColor {
+	p.pos++
+	if cur != 0 {
+		return -1
+	}
+	return bond
+}
+
+func (p *smilesParser) ringClosure(atomIdx int, bond orca.EdgeColor) error {
+	start := p.pos
+	var digit int
+	if p.str[p.pos] == '%' {
+		if p.pos+2 >= len(p.str) || !isDigit(p.str[p.pos+1]) || !isDigit(p.str[p.pos+2]) {
+			return p.errorf("'%%' must be followed by two digits")
+		}
+		digit = int(p.str[p.pos+1]-'0')*10 + int(p.str[p.pos+2]-'0')
+		p.pos += 3
+	} else {
+		digit = int(p.str[p.pos] - '0')
+		p.pos++
+	}
+
+	opening, open := p.rings[digit]
+	if !open {
+		p.rings[digit] = ringBond{
+			atom:  atomIdx,
+			color: bond,
+			pos:   start,
+		}
+		return nil
+	}
+
+	delete(p.rings, digit)
+	if opening.color != 0 && bond != 0 && opening.color != bond {
+		return p.errorf("ring %d has conflicting bond symbols", digit)
+	}
+	if bond == 0 {
+		bond = opening.color
+	}
+	return p.addBond(opening.atom, atomIdx, bond)
+}
+
+func (p *smilesParser) addBond(a, b int, bond orca.EdgeColor) error {
+	if a == b {
+		return p.errorf("atom bonded to itself")
+	}
+	for _, e := range p.edges {
+		if (int(e.Va) == a+1 && int(e.Vb) == b+1) || (int(e.Va) == b+1 && int(e.Vb) == a+1) {
+			return p.errorf("duplicate bond")
+		}
+	}
+	if bond == 0 {
+		if p.atoms[a].aromatic && p.atoms[b].aromatic {
+			bond = BondAromatic
+		} else {
+			bond = BondSingle
+		}
+	}
+
+	order := int(bond)
+	if bond == BondAromatic {
+		order = 1
+	}
+	p.atoms[a].bondSum += order
+	p.atoms[b].bondSum += order
+
+	p.edges = append(p.edges, orca.Edge{
+		Va:    orca.VtxLabel(a + 1),
+		Vb:    orca.VtxLabel(b + 1),
+		Color: bond,
+	})
+	return nil
+}
+
+// parseAtom parses an organic subset atom or a bracket atom, returning its index.
+func (p *smilesParser) parseAtom() (int, error) {
+	var atom smilesAtom
+
+	if p.str[p.pos] == '[' {
+		if err := p.parseBracketAtom(&atom); err != nil {
+			return 0, err
+		}
+	} else {
+		atom.organic = true
+
+		sym := p.str[p.pos:min(p.pos+2, len(p.str))]
+		if sym != "Cl" && sym != "Br" {
+			sym = sym[:1]
+		}
+		if e, found := aromaticSymbols[sym]; found && len(sym) == 1 {
+			atom.Element = e
+			atom.aromatic = true
+		} else if e, found := ElementForSymbol(sym); found && organicValences[e] != nil {
+			atom.Element = e
+		} else if sym == "*" {
+			return 0, p.errorf("wildcard atoms are not supported")
+		} else {
+			return 0, p.errorf("unexpected %q", sym)
+		}
+		p.pos += len(sym)
+	}
+
+	p.atoms = append(p.atoms, atom)
+	return len(p.atoms) - 1, nil
+}
+
+func (p *smilesParser) parseBracketAtom(atom *smilesAtom) error {
+	p.pos++ // '['
+
+	atom.Isotope = p.parseNumber(0)
+
+	// Element symbol (or aromatic symbol)
+	{
+		rest := p.str[p.pos:]
+		switch {
+		case len(rest) >= 2 && aromaticSymbols[rest[:2]] != 0:
+			atom.Element = aromaticSymbols[rest[:2]]
+			atom.aromatic = true
+			p.pos += 2
+		case len(rest) >= 1 && aromaticSymbols[rest[:1]] != 0:
+			atom.Element = aromaticSymbols[rest[:1]]
+			atom.aromatic = true
+			p.pos++
+		case len(rest) >= 2 && isUpper(rest[0]) && elementBySymbol[rest[:2]] != 0:
+			atom.Element = elementBySymbol[rest[:2]]
+			p.pos += 2
+		case len(rest) >= 1 && elementBySymbol[rest[:1]] != 0:
+			atom.Element = elementBySymbol[rest[:1]]
+			p.pos++
+		case len(rest) >= 1 && rest[0] == '*':
+			return p.errorf("wildcard atoms are not supported")
+		default:
+			return p.errorf("expected element symbol")
+		}
+	}
+
+	// Chirality (ignored)
+	if p.pos < len(p.str) && p.str[p.pos] == '@' {
+		for p.pos < len(p.str) && p.str[p.pos] == '@' {
+			p.pos++
+		}
+		for _, class := range []string{"TH", "AL", "SP", "TB", "OH"} {
+			if len(p.str) >= p.pos+2 && p.str[p.pos:p.pos+2] == class {
+				p.pos += 2
+				p.parseNumber(0)
+				break
+			}
+		}
+	}
+
+	// Hydrogen count
+	if p.pos < len(p.str) && p.str[p.pos] == 'H' {
+		p.pos++
+		atom.HCount = p.parseNumber(1)
+	}
+
+	// Charge
+	if p.pos < len(p.str) && (p.str[p.pos] == '+' || p.str[p.pos] == '-') {
+		sign := 1
+		if p.str[p.pos] == '-' {
+			sign = -1
+		}
+		symbol := p.str[p.pos]
+		p.pos++
+		if p.pos < len(p.str) && isDigit(p.str[p.pos]) {
+			atom.Charge = sign * p.parseNumber(1)
+		} else {
+			atom.Charge = sign
+			for p.pos < len(p.str) && p.str[p.pos] == symbol {
+				atom.Charge += sign
+				p.pos++
+			}
+		}
+	}
+
+	// Atom class (ignored)
+	if p.pos < len(p.str) && p.str[p.pos] == ':' {
+		p.pos++
+		p.parseNumber(0)
+	}
+
+	if p.pos >= len(p.str) || p.str[p.pos] != ']' {
+		return p.errorf("expected ']'")
+	}
+	p.pos++
+	return nil
+}
+
+// parseNumber parses a decimal number, returning the given default if no digits are present.
+func (p *smilesParser) parseNumber(defaultVal int) int {
+	start := p.pos
+	for p.pos < len(p.str) && isDigit(p.str[p.pos]) {
+		p.pos++
+	}
+	if start == p.pos {
+		return defaultVal
+	}
+	N, _ := strconv.Atoi(p.str[start:p.pos])
+	return N
+}
+
+// graph infers implicit hydrogens and emits the parsed atoms and bonds as a graph.
+func (p *smilesParser) graph() (*orca.Graph, error) {
+	G := &orca.Graph{
+		Vtx:   make([]orca.Vtx, len(p.atoms)),
+		Edges: p.edges,
+	}
+
+	for i := range p.atoms {
+		atom := &p.atoms[i]
+		if atom.organic {
+			atom.HCount = implicitHCount(atom)
+		}
+		color, err := atom.VtxColor()
+		if err != nil {
+			return nil, errors.Wrapf(ErrBadSMILES, "atom %d: %v", i+1, err)
+		}
+		G.Vtx[i] = orca.Vtx{
+			Label: orca.VtxLabel(i + 1),
+			Color: color,
+		}
+	}
+	return G, nil
+}
+
+// implicitHCount returns the hydrogens implied by an organic subset atom.
+// Aromatic atoms count one extra bond (their share of the pi system) and only consider their lowest normal valence.
+func implicitHCount(atom *smilesAtom) int {
+	valences := organicValences[atom.Element]
+	bondSum := atom.bondSum
+	if atom.aromatic {
+		bondSum++
+		valences = valences[:1]
+	}
+	for _, valence := range valences {
+		if valence >= bondSum {
+			return valence - bondSum
+		}
+	}
+	return 0
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isUpper(ch byte) bool {
+	return ch >= 'A' && ch <= 'Z'
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
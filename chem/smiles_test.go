@@ -0,0 +1,54 @@
+package chem
+
+import (
+	"testing"
+
+	"github.com/3x2theory/go-orca"
+)
+
+func TestParseSMILES(t *testing.T) {
+	for _, tc := range []struct {
+		smiles  string
+		Nv, Ne  int
+		atom1   Atom
+		bond1   orca.EdgeColor
+		wantErr bool
+	}{
+		{smiles: "CCO", Nv: 3, Ne: 2, atom1: Atom{Element: C, HCount: 3}, bond1: BondSingle},
+		{smiles: "c1ccccc1", Nv: 6, Ne: 6, atom1: Atom{Element: C, HCount: 1}, bond1: BondAromatic},
+		{smiles: "O=C(O)C#N", Nv: 5, Ne: 4, atom1: Atom{Element: O}, bond1: BondDouble},
+		{smiles: "[13CH3][NH3+]", Nv: 2, Ne: 1, atom1: Atom{Element: C, Isotope: 13, HCount: 3}, bond1: BondSingle},
+		{smiles: "[O--].[Na+].[Na+]", Nv: 3, Ne: 0, atom1: Atom{Element: O, Charge: -2}},
+		{smiles: "C%12CC%12", Nv: 3, Ne: 3, atom1: Atom{Element: C, HCount: 2}, bond1: BondSingle},
+		{smiles: "c1cc[nH]c1", Nv: 5, Ne: 5, atom1: Atom{Element: C, HCount: 1}, bond1: BondAromatic},
+		{smiles: "C1=CC=CC=C1Cl", Nv: 7, Ne: 7, atom1: Atom{Element: C, HCount: 1}, bond1: BondDouble},
+		{smiles: "F[C@@H](Cl)Br", Nv: 4, Ne: 3, atom1: Atom{Element: F}, bond1: BondSingle},
+		{smiles: "C1CC", wantErr: true},
+		{smiles: "C(C", wantErr: true},
+		{smiles: "C=1CC-1", wantErr: true},
+		{smiles: "[Xx]", wantErr: true},
+		{smiles: "C==C", wantErr: true},
+	} {
+		G, err := ParseSMILES(tc.smiles)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error", tc.smiles)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.smiles, err)
+			continue
+		}
+		if len(G.Vtx) != tc.Nv || len(G.Edges) != tc.Ne {
+			t.Errorf("%s: expected %d atoms and %d bonds, got %v", tc.smiles, tc.Nv, tc.Ne, G)
+			continue
+		}
+		if atom := AtomForColor(G.Vtx[0].Color); atom != tc.atom1 {
+			t.Errorf("%s: expected first atom %+v, got %+v", tc.smiles, tc.atom1, atom)
+		}
+		if tc.Ne > 0 && G.Edges[0].Color != tc.bond1 {
+			t.Errorf("%s: expected first bond %v, got %v", tc.smiles, tc.bond1, G.Edges[0].Color)
+		}
+	}
+}
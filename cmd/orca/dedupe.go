@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/3x2theory/go-orca"
+)
+
+// dedupe writes the canonic form of the first graph of each isomorphism class and optionally a report listing each
+// graph's class and id (tab separated, with the id quoted as a Go string), grouped by class in order of occurrence.
+func (c *cli) dedupe(args []string) int {
+	outFormat := c.flags.String("o", fmtText, "output format: "+strings.Join(outputFormats, ", "))
+	reportPath := c.flags.String("report", "", "write the class report to this file")
+	maxMem := c.flags.Int("mem", 1<<20, "graph classes held in memory before spilling to disk")
+	tempDir := c.flags.String("tmp", "", "directory for spill files (default: system temp dir)")
+	if !c.parseFlags(args) || !c.checkOutputFormat(*outFormat) {
+		return exitErr
+	}
+
+	D, err := orca.NewDeduper(nil, orca.DedupeOpts{
+		MaxMemClasses: *maxMem,
+		TempDir:       *tempDir,
+	})
+	if err != nil {
+		fmt.Fprintf(c.stderr, "orca: %v\n", err)
+		return exitErr
+	}
+	defer D.Close()
+
+	err = c.forEachCanonic(c.flags.Args(), func(rec *record, C *orca.Canonic, canonizer orca.IGraphCanonizer) error {
+		res, err := D.AddEncoding(rec.id, C.Encoding())
+		if err != nil || !res.First {
+			return err
+		}
+		return writeCanonic(c.stdout, *outFormat, rec, C, canonizer, c.attrs())
+	})
+	if err != nil {
+		c.warn("%v", err)
+	}
+
+	if *reportPath != "" {
+		if err = writeDedupeReport(*reportPath, D); err != nil {
+			c.warn("%v", err)
+		}
+	}
+	return c.exitCode()
+}
+
+func writeDedupeReport(path string, D *orca.Deduper) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(file)
+	fmt.Fprintf(out, "# %d graphs, %d classes\n", D.NumGraphs(), D.NumClasses())
+	err = D.Classes(func(class orca.DedupeClass) error {
+		return D.IDs(class.Class, func(id string) error {
+			_, err := fmt.Fprintf(out, "%d\t%s\n", class.Class, strconv.Quote(id))
+			return err
+		})
+	})
+	if err == nil {
+		err = out.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
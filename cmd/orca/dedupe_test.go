@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "report.tsv")
+	out, code := runCLI(t, "OCC ethanol, \"abs\"\nCCO\nc1ccccc1\nC(O)C\n", "dedupe", "-f", "smiles", "-o", "string", "-mem", "1", "-report", reportPath)
+	if code != exitOK || len(out) == 0 {
+		t.Fatalf("unexpected dedupe result (%d): %q", code, out)
+	}
+	hashes, _ := runCLI(t, "OCC\nc1ccccc1\n", "hash", "-f", "smiles")
+	if out != hashes {
+		t.Fatalf("expected:\n%s\ngot:\n%s", hashes, out)
+	}
+
+	report, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# 4 graphs, 2 classes\n0\t\"ethanol, \\\"abs\\\"\"\n0\t\"stdin:2\"\n0\t\"stdin:4\"\n1\t\"stdin:3\"\n"
+	if string(report) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, report)
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/3x2theory/go-orca"
+	"github.com/3x2theory/go-orca/chem"
+	"github.com/pkg/errors"
+)
+
+// Input formats, also used as output formats (along with graph6Formats and hashFormats)
+const (
+	fmtText    = "text"
+	fmtDIMACS  = "dimacs"
+	fmtGraph6  = "graph6"
+	fmtGraphML = "graphml"
+	fmtJSON    = "json"
+	fmtSMILES  = "smiles"
+	fmtSDF     = "sdf"
+)
+
+var inputFormats = []string{fmtText, fmtDIMACS, fmtGraph6, fmtGraphML, fmtJSON, fmtSMILES, fmtSDF}
+
+var graph6Formats = map[string]orca.Graph6Format{
+	fmtGraph6:  orca.Graph6,
+	"sparse6":  orca.Sparse6,
+	"digraph6": orca.Digraph6,
+}
+
+var formatForExt = map[string]string{
+	".txt":     fmtText,
+	".dimacs":  fmtDIMACS,
+	".col":     fmtDIMACS,
+	".dim":     fmtDIMACS,
+	".g6":      fmtGraph6,
+	".s6":      fmtGraph6,
+	".d6":      fmtGraph6,
+	".graphml": fmtGraphML,
+	".json":    fmtJSON,
+	".smi":     fmtSMILES,
+	".smiles":  fmtSMILES,
+	".sdf":     fmtSDF,
+	".mol":     fmtSDF,
+}
+
+// inputFormat returns the format to read the given path in, where an explicit format takes precedence.
+func inputFormat(path, explicit string) (string, error) {
+	if explicit != "" {
+		for _, format := range inputFormats {
+			if format == explicit {
+				return format, nil
+			}
+		}
+		return "", errors.Errorf("unknown input format %q (expected one of %s)", explicit, strings.Join(inputFormats, ", "))
+	}
+	if format, known := formatForExt[strings.ToLower(filepath.Ext(path))]; known {
+		return format, nil
+	}
+	return fmtText, nil
+}
+
+// isChem returns true if the given format uses the chem color scheme.
+func isChem(format string) bool {
+	return format == fmtSMILES || format == fmtSDF
+}
+
+// record is a single graph read from an input, along with its name (if the format has one).
+type record struct {
+	name string
+	id   string // name if set, otherwise "<path>:<record number>"
+	G    *orca.Graph
+	mol  *chem.Molecule // set for molfile records so coordinates and data survive canonization
+}
+
+// recordReader returns successive records, returning io.EOF when there are no more.
+// A badRecord error means only that record was unreadable, so reading can continue.
+type recordReader interface {
+	Next() (*record, error)
+}
+
+type badRecord struct {
+	error
+}
+
+func newRecordReader(r io.Reader, format string, attrs orca.AttrMapping) recordReader {
+	switch format {
+	case fmtGraph6:
+		return graph6Records{orca.NewGraph6Reader(r)}
+	case fmtSDF:
+		return sdfRecords{chem.NewSDFReader(r)}
+	case fmtText, fmtSMILES:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 256*1024*1024)
+		return &lineRecords{
+			scanner: scanner,
+			format:  format,
+		}
+	default:
+		return &wholeRecord{
+			r:      r,
+			format: format,
+			attrs:  attrs,
+		}
+	}
+}
+
+type graph6Records struct {
+	*orca.Graph6Reader
+}
+
+func (R graph6Records) Next() (*record, error) {
+	G, err := R.Graph6Reader.Next()
+	if errors.Cause(err) == orca.ErrBadGraph6 {
+		return nil, badRecord{err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record{G: G}, nil
+}
+
+type sdfRecords struct {
+	*chem.SDFReader
+}
+
+func (R sdfRecords) Next() (*record, error) {
+	mol, err := R.SDFReader.Next()
+	if errors.Cause(err) == chem.ErrBadMolfile {
+		return nil, badRecord{err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record{
+		name: mol.Name,
+		G:    mol.Graph,
+		mol:  mol,
+	}, nil
+}
+
+// lineRecords reads one graph per line, skipping blank lines and lines starting with '#'.
+// A SMILES line may be followed by a name, as is conventional for .smi files.
+type lineRecords struct {
+	scanner *bufio.Scanner
+	format  string
+	lineNum int
+}
+
+func (R *lineRecords) Next() (*record, error) {
+	for R.scanner.Scan() {
+		R.lineNum++
+		line := strings.TrimSpace(R.scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		rec := &record{}
+		var err error
+		if R.format == fmtSMILES {
+			fields := strings.Fields(line)
+			rec.name = strings.Join(fields[1:], " ")
+			rec.G, err = chem.ParseSMILES(fields[0])
+		} else {
+			rec.G, err = orca.ParseGraphText(line)
+		}
+		if err != nil {
+			return nil, badRecord{errors.Wrapf(err, "line %d", R.lineNum)}
+		}
+		return rec, nil
+	}
+	if err := R.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// wholeRecord reads a single graph from the entire input.
+type wholeRecord struct {
+	r      io.Reader
+	format string
+	attrs  orca.AttrMapping
+	done   bool
+}
+
+func (R *wholeRecord) Next() (*record, error) {
+	if R.done {
+		return nil, io.EOF
+	}
+	R.done = true
+
+	var G *orca.Graph
+	var err error
+	switch R.format {
+	case fmtDIMACS:
+		G, err = orca.ReadDIMACS(R.r)
+	case fmtGraphML:
+		G, err = orca.ReadGraphML(R.r, R.attrs)
+	case fmtJSON:
+		G, err = orca.ReadNodeLink(R.r, R.attrs)
+	default:
+		err = errors.Errorf("unknown input format %q", R.format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record{G: G}, nil
+}
+
+// writeCanonic writes the canonic form of a record in the given output format.
+func writeCanonic(w io.Writer, format string, rec *record, C *orca.Canonic, canonizer orca.IGraphCanonizer, attrs orca.AttrMapping) error {
+	if g6Format, isGraph6 := graph6Formats[format]; isGraph6 {
+		line, err := orca.AppendGraph6(nil, &C.Graph, g6Format)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", line)
+		return err
+	}
+
+	switch format {
+	case fmtText:
+		_, err := fmt.Fprintln(w, C.Graph.String())
+		return err
+	case "string":
+		_, err := fmt.Fprintln(w, C.CanonicString())
+		return err
+	case fmtDIMACS:
+		if rec.name != "" {
+			fmt.Fprintf(w, "c %s\n", rec.name)
+		}
+		return orca.WriteDIMACS(w, &C.Graph)
+	case fmtGraphML:
+		return orca.WriteGraphML(w, C, attrs)
+	case fmtJSON:
+		return orca.WriteNodeLink(w, C, attrs)
+	case fmtSMILES:
+		smiles, err := chem.WriteSMILES(&C.Graph)
+		if err != nil {
+			return err
+		}
+		if rec.name != "" {
+			smiles += " " + rec.name
+		}
+		_, err = fmt.Fprintln(w, smiles)
+		return err
+	case fmtSDF:
+		mol := rec.mol
+		if mol == nil {
+			mol = &chem.Molecule{
+				Name:  rec.name,
+				Graph: rec.G,
+			}
+		}
+		canonicMol, err := chem.CanonicMolecule(canonizer, mol)
+		if err != nil {
+			return err
+		}
+		return chem.WriteSDF(w, canonicMol, chem.MolfileV2000)
+	}
+	return errors.Errorf("unknown output format %q", format)
+}
+
+var hashFormats = []string{"base32", "sha256"}
+
+// formatHash returns the canonic string ("base32") or the hex SHA-256 of the canonic encoding ("sha256").
+func formatHash(C *orca.Canonic, format string) (string, error) {
+	switch format {
+	case "base32":
+		return C.CanonicString(), nil
+	case "sha256":
+		sum := sha256.Sum256(C.Encoding())
+		return hex.EncodeToString(sum[:]), nil
+	}
+	return "", errors.Errorf("unknown hash format %q (expected one of %s)", format, strings.Join(hashFormats, ", "))
+}
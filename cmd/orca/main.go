@@ -0,0 +1,287 @@
+/*
+Command orca canonizes graphs, tests graphs for isomorphism, and prints canonical hashes.
+
+Usage:
+
+	orca canon  [flags] [file ...]     write the canonic form of each graph
+	orca iso    [flags] fileA fileB    test the graphs of fileA and fileB for isomorphism, pairwise by record
+	orca hash   [flags] [file ...]     print the canonic string (or SHA-256) of each graph
+	orca dedupe [flags] [file ...]     write the canonic form of only the first graph of each isomorphism class
+	orca serve  [flags]                serve canonization over HTTP JSON (see package server)
+
+Files are read in the format given by -f or else inferred from their extension, and stdin is read when no file
+(or "-") is given.  Multi-record inputs (text and SMILES lines, graph6 lines, SDF) are processed record by record.
+Run "orca <command> -h" for each command's flags.
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/3x2theory/go-orca"
+	"github.com/3x2theory/go-orca/chem"
+	"github.com/pkg/errors"
+)
+
+// Exit codes
+const (
+	exitOK     = 0
+	exitFailed = 1 // a record failed, or graphs are not isomorphic
+	exitErr    = 2 // bad usage or unreadable input
+)
+
+var outputFormats = []string{fmtText, "string", fmtDIMACS, fmtGraph6, "sparse6", "digraph6", fmtGraphML, fmtJSON, fmtSMILES, fmtSDF}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// cli holds the I/O and flags shared by all commands.
+type cli struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+	flags          *flag.FlagSet
+	inFormat       string
+	vtxAttr        string
+	edgeAttr       string
+	failed         bool
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: orca <canon|iso|hash|dedupe|serve> [flags] [file ...]")
+		return exitErr
+	}
+
+	c := &cli{
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+		flags:  flag.NewFlagSet("orca "+args[0], flag.ContinueOnError),
+	}
+	c.flags.SetOutput(stderr)
+	c.flags.StringVar(&c.inFormat, "f", "", "input format: "+strings.Join(inputFormats, ", ")+" (default: by file extension, else text)")
+	c.flags.StringVar(&c.vtxAttr, "vtx-attr", "color", "GraphML/JSON node attribute holding the vertex color")
+	c.flags.StringVar(&c.edgeAttr, "edge-attr", "color", "GraphML/JSON edge attribute holding the edge color")
+
+	switch args[0] {
+	case "canon":
+		return c.canon(args[1:])
+	case "iso":
+		return c.iso(args[1:])
+	case "hash":
+		return c.hash(args[1:])
+	case "dedupe":
+		return c.dedupe(args[1:])
+	case "serve":
+		return c.serve(args[1:])
+	}
+	fmt.Fprintf(stderr, "orca: unknown command %q (expected canon, iso, hash, dedupe, or serve)\n", args[0])
+	return exitErr
+}
+
+func (c *cli) attrs() orca.AttrMapping {
+	return orca.AttrMapping{
+		VtxAttr:  c.vtxAttr,
+		EdgeAttr: c.edgeAttr,
+	}
+}
+
+func (c *cli) warn(format string, args ...interface{}) {
+	c.failed = true
+	fmt.Fprintf(c.stderr, "orca: "+format+"\n", args...)
+}
+
+func (c *cli) exitCode() int {
+	if c.failed {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// forEachCanonic canonizes each record of each input path (or stdin), reporting and skipping records that fail.
+func (c *cli) forEachCanonic(paths []string, handler func(rec *record, C *orca.Canonic, canonizer orca.IGraphCanonizer) error) error {
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	for _, path := range paths {
+		err := c.readRecords(path, func(recNum int, rec *record, err error, canonizer orca.IGraphCanonizer) {
+			var C *orca.Canonic
+			if err == nil {
+				C, err = orca.CanonizeGraph(canonizer, rec.G)
+			}
+			if err == nil {
+				err = handler(rec, C, canonizer)
+			}
+			if err != nil {
+				c.warn("%s: record %d: %v", displayPath(path), recNum, err)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readRecords reads every record of the given path ("-" for stdin), passing any badRecord error to the handler and
+// stopping at any other read error.
+func (c *cli) readRecords(path string, handler func(recNum int, rec *record, err error, canonizer orca.IGraphCanonizer)) error {
+	format, err := inputFormat(path, c.inFormat)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = c.stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	opts := orca.DefaultCanonizerOpts
+	if isChem(format) {
+		opts = chem.CanonizerOpts()
+	}
+	canonizer := orca.NewCanonizer(opts)
+
+	records := newRecordReader(r, format, c.attrs())
+	for recNum := 1; ; recNum++ {
+		rec, err := records.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if _, isBad := err.(badRecord); err != nil && !isBad {
+			return errors.Wrapf(err, "%s: record %d", displayPath(path), recNum)
+		}
+		if rec != nil {
+			rec.id = rec.name
+			if rec.id == "" {
+				rec.id = fmt.Sprintf("%s:%d", displayPath(path), recNum)
+			}
+		}
+		handler(recNum, rec, err, canonizer)
+	}
+}
+
+func displayPath(path string) string {
+	if path == "-" {
+		return "stdin"
+	}
+	return path
+}
+
+// parseFlags parses the command's flags, returning false if the command should exit with exitErr.
+func (c *cli) parseFlags(args []string) bool {
+	return c.flags.Parse(args) == nil
+}
+
+func (c *cli) checkOutputFormat(outFormat string) bool {
+	for _, format := range outputFormats {
+		if format == outFormat {
+			return true
+		}
+	}
+	fmt.Fprintf(c.stderr, "orca: unknown output format %q (expected one of %s)\n", outFormat, strings.Join(outputFormats, ", "))
+	return false
+}
+
+func (c *cli) canon(args []string) int {
+	outFormat := c.flags.String("o", fmtText, "output format: "+strings.Join(outputFormats, ", "))
+	if !c.parseFlags(args) || !c.checkOutputFormat(*outFormat) {
+		return exitErr
+	}
+
+	err := c.forEachCanonic(c.flags.Args(), func(rec *record, C *orca.Canonic, canonizer orca.IGraphCanonizer) error {
+		return writeCanonic(c.stdout, *outFormat, rec, C, canonizer, c.attrs())
+	})
+	if err != nil {
+		c.warn("%v", err)
+	}
+	return c.exitCode()
+}
+
+func (c *cli) hash(args []string) int {
+	hashFormat := c.flags.String("o", "base32", "hash format: "+strings.Join(hashFormats, ", "))
+	if !c.parseFlags(args) {
+		return exitErr
+	}
+	if _, err := formatHash(&orca.Canonic{}, *hashFormat); err != nil {
+		fmt.Fprintf(c.stderr, "orca: %v\n", err)
+		return exitErr
+	}
+
+	err := c.forEachCanonic(c.flags.Args(), func(rec *record, C *orca.Canonic, canonizer orca.IGraphCanonizer) error {
+		hash, _ := formatHash(C, *hashFormat)
+		if rec.name != "" {
+			hash += "\t" + rec.name
+		}
+		_, err := fmt.Fprintln(c.stdout, hash)
+		return err
+	})
+	if err != nil {
+		c.warn("%v", err)
+	}
+	return c.exitCode()
+}
+
+// iso compares the i-th record of each file, printing one line per pair, and exits with exitFailed unless every
+// pair is isomorphic.
+func (c *cli) iso(args []string) int {
+	quiet := c.flags.Bool("q", false, "print nothing; only set the exit code")
+	if !c.parseFlags(args) {
+		return exitErr
+	}
+	if c.flags.NArg() != 2 {
+		fmt.Fprintln(c.stderr, "usage: orca iso [flags] fileA fileB")
+		return exitErr
+	}
+
+	var encodings [2][]orca.GraphEncoding
+	for i, path := range c.flags.Args() {
+		err := c.forEachCanonic([]string{path}, func(rec *record, C *orca.Canonic, canonizer orca.IGraphCanonizer) error {
+			encodings[i] = append(encodings[i], C.Encoding())
+			return nil
+		})
+		if err != nil {
+			c.warn("%v", err)
+		}
+	}
+	if c.failed {
+		return exitErr
+	}
+	if len(encodings[0]) != len(encodings[1]) {
+		fmt.Fprintf(c.stderr, "orca: record counts differ (%d vs %d)\n", len(encodings[0]), len(encodings[1]))
+		return exitErr
+	}
+
+	allIso := true
+	for i := range encodings[0] {
+		isIso := bytes.Equal(encodings[0][i], encodings[1][i])
+		allIso = allIso && isIso
+		if *quiet {
+			continue
+		}
+		verdict := "isomorphic"
+		if !isIso {
+			verdict = "not isomorphic"
+		}
+		if len(encodings[0]) > 1 {
+			fmt.Fprintf(c.stdout, "%d\t%s\n", i+1, verdict)
+		} else {
+			fmt.Fprintln(c.stdout, verdict)
+		}
+	}
+	if !allIso {
+		return exitFailed
+	}
+	return exitOK
+}
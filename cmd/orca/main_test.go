@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runCLI(t *testing.T, stdin string, args ...string) (string, int) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := run(args, strings.NewReader(stdin), stdout, stderr)
+	if stderr.Len() > 0 {
+		t.Log(stderr.String())
+	}
+	return stdout.String(), code
+}
+
+func TestCLI(t *testing.T) {
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a.smi")
+	pathB := filepath.Join(dir, "b.smi")
+	os.WriteFile(pathA, []byte("OCC ethanol\nc1ccccc1O phenol\n"), 0644)
+	os.WriteFile(pathB, []byte("CCO\nOc1ccccc1\n"), 0644)
+
+	out, code := runCLI(t, "", "iso", pathA, pathB)
+	if code != exitOK || out != "1\tisomorphic\n2\tisomorphic\n" {
+		t.Fatalf("unexpected iso result (%d): %q", code, out)
+	}
+
+	// Batch hashing over stdin, with names carried through
+	out, code = runCLI(t, "OCC ethanol\nCCO\n", "hash", "-f", "smiles")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if code != exitOK || len(lines) != 2 || lines[0] != lines[1]+"\tethanol" {
+		t.Fatalf("unexpected hash result (%d): %q", code, out)
+	}
+
+	out, code = runCLI(t, "", "canon", "-o", "smiles", pathA)
+	if code != exitOK || !strings.HasSuffix(strings.Split(out, "\n")[0], " ethanol") {
+		t.Fatalf("unexpected canon result (%d): %q", code, out)
+	}
+
+	// The text notation is the default for stdin, and a bad record doesn't stop the batch
+	out, code = runCLI(t, "1-2 2-3\n1-1\n3-1 1-2\n", "canon", "-o", "graph6")
+	if code != exitFailed || out != "Bo\nBo\n" {
+		t.Fatalf("unexpected canon result (%d): %q", code, out)
+	}
+
+	// Likewise for a bad SDF record
+	sdf := "bad\n\n\n  1  0  0  0  0  0  0  0  0  0999 V2000\n    0.0000    0.0000    0.0000 Xx  0  0\nM  END\n$$$$\n" +
+		"methane\n\n\n  1  0  0  0  0  0  0  0  0  0999 V2000\n    0.0000    0.0000    0.0000 C   0  0\nM  END\n$$$$\n"
+	out, code = runCLI(t, sdf, "canon", "-f", "sdf", "-o", "smiles")
+	if code != exitFailed || out != "C methane\n" {
+		t.Fatalf("unexpected canon result (%d): %q", code, out)
+	}
+
+	out, code = runCLI(t, "", "iso", "-q", pathA, filepath.Join(dir, "missing.smi"))
+	if code != exitErr {
+		t.Fatalf("expected error exit, got %d: %q", code, out)
+	}
+	if _, code = runCLI(t, "", "canon", "-o", "png"); code != exitErr {
+		t.Fatalf("expected usage error, got %d", code)
+	}
+}
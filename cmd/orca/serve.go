@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/3x2theory/go-orca/server"
+)
+
+// serve runs the go-orca HTTP JSON service (see package server) until it fails.
+func (c *cli) serve(args []string) int {
+	addr := c.flags.String("addr", "localhost:8080", "address to listen on")
+	maxBytes := c.flags.Int64("max-bytes", server.DefaultOpts.MaxRequestBytes, "maximum request size in bytes")
+	timeout := c.flags.Duration("timeout", server.DefaultOpts.Timeout, "per-request canonization timeout")
+	maxConcurrent := c.flags.Int("max-concurrent", server.DefaultOpts.MaxConcurrent, "most requests canonizing at once")
+	if !c.parseFlags(args) {
+		return exitErr
+	}
+
+	opts := server.DefaultOpts
+	opts.MaxRequestBytes = *maxBytes
+	opts.Timeout = *timeout
+	opts.MaxConcurrent = *maxConcurrent
+	opts.Attrs = c.attrs()
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           server.NewHandler(opts),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       *timeout + 10*time.Second,
+		WriteTimeout:      *timeout + 10*time.Second,
+	}
+	fmt.Fprintf(c.stderr, "orca: serving on http://%s\n", *addr)
+	if err := srv.ListenAndServe(); err != nil {
+		fmt.Fprintf(c.stderr, "orca: %v\n", err)
+	}
+	return exitErr
+}
@@ -0,0 +1,211 @@
+package orca
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// EdgeColorDef is the EdgeColor counterpart of VtxColorDef.
+type EdgeColorDef struct {
+	NameAscii string
+	NameUTF8  string
+	Aliases   []string
+	Desc      string
+	EdgeColor int64 // Always > 0 and unique amongst other EdgeColorDefs.
+}
+
+var (
+	ErrColorConflict = errors.New("color definition conflict")
+	ErrColorNotFound = errors.New("color name not found")
+)
+
+// VtxColorRegistry maps VtxColorDefs to and from names, allowing teams to agree on (and share) VtxColor assignments.
+//
+// A registry is not safe for concurrent writes, but once populated, it can be shared by any number of canonizers.
+type VtxColorRegistry struct {
+	defs  map[VtxColor]VtxColorDef
+	names colorNames
+}
+
+// EdgeColorRegistry is the EdgeColor counterpart of VtxColorRegistry.
+type EdgeColorRegistry struct {
+	defs  map[EdgeColor]EdgeColorDef
+	names colorNames
+}
+
+func NewVtxColorRegistry() *VtxColorRegistry {
+	return &VtxColorRegistry{
+		defs:  make(map[VtxColor]VtxColorDef, 16),
+		names: make(colorNames, 32),
+	}
+}
+
+func NewEdgeColorRegistry() *EdgeColorRegistry {
+	return &EdgeColorRegistry{
+		defs:  make(map[EdgeColor]EdgeColorDef, 8),
+		names: make(colorNames, 16),
+	}
+}
+
+// AddVtxColors registers the given defs, failing (and adding none of them) if any color or name is already taken.
+func (R *VtxColorRegistry) AddVtxColors(defs []VtxColorDef) error {
+	pending := make(colorNames, 4*len(defs))
+	added := make(map[VtxColor]struct{}, len(defs))
+	for _, def := range defs {
+		color := VtxColor(def.VtxColor)
+		if def.VtxColor <= 0 {
+			return errors.Wrapf(ErrColorConflict, "VtxColor for %q must be > 0", def.NameAscii)
+		}
+		_, exists := R.defs[color]
+		if _, dupe := added[color]; exists || dupe {
+			return errors.Wrapf(ErrColorConflict, "VtxColor %d already defined", color)
+		}
+		added[color] = struct{}{}
+		if err := pending.add(R.names, def.VtxColor, def.NameAscii, def.NameUTF8, def.Aliases); err != nil {
+			return err
+		}
+	}
+
+	for _, def := range defs {
+		R.defs[VtxColor(def.VtxColor)] = def
+	}
+	for name, color := range pending {
+		R.names[name] = color
+	}
+	return nil
+}
+
+// Def returns the VtxColorDef for the given color.
+func (R *VtxColorRegistry) Def(color VtxColor) (VtxColorDef, bool) {
+	def, found := R.defs[color]
+	return def, found
+}
+
+// Resolve returns the VtxColor having the given name, UTF8 name, or alias.
+func (R *VtxColorRegistry) Resolve(name string) (VtxColor, error) {
+	color, found := R.names[name]
+	if !found {
+		return 0, errors.Wrapf(ErrColorNotFound, "no VtxColor named %q", name)
+	}
+	return VtxColor(color), nil
+}
+
+// Name returns the ASCII name of the given color, or "" if the color is not registered.
+func (R *VtxColorRegistry) Name(color VtxColor) string {
+	return R.defs[color].NameAscii
+}
+
+// Defs returns all registered VtxColorDefs, ordered by VtxColor.
+func (R *VtxColorRegistry) Defs() []VtxColorDef {
+	defs := make([]VtxColorDef, 0, len(R.defs))
+	for _, def := range R.defs {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].VtxColor < defs[j].VtxColor
+	})
+	return defs
+}
+
+// AddEdgeColors registers the given defs, failing (and adding none of them) if any color or name is already taken.
+func (R *EdgeColorRegistry) AddEdgeColors(defs []EdgeColorDef) error {
+	pending := make(colorNames, 4*len(defs))
+	added := make(map[EdgeColor]struct{}, len(defs))
+	for _, def := range defs {
+		color := EdgeColor(def.EdgeColor)
+		if def.EdgeColor <= 0 {
+			return errors.Wrapf(ErrColorConflict, "EdgeColor for %q must be > 0", def.NameAscii)
+		}
+		_, exists := R.defs[color]
+		if _, dupe := added[color]; exists || dupe {
+			return errors.Wrapf(ErrColorConflict, "EdgeColor %d already defined", color)
+		}
+		added[color] = struct{}{}
+		if err := pending.add(R.names, def.EdgeColor, def.NameAscii, def.NameUTF8, def.Aliases); err != nil {
+			return err
+		}
+	}
+
+	for _, def := range defs {
+		R.defs[EdgeColor(def.EdgeColor)] = def
+	}
+	for name, color := range pending {
+		R.names[name] = color
+	}
+	return nil
+}
+
+// Def returns the EdgeColorDef for the given color.
+func (R *EdgeColorRegistry) Def(color EdgeColor) (EdgeColorDef, bool) {
+	def, found := R.defs[color]
+	return def, found
+}
+
+// Resolve returns the EdgeColor having the given name, UTF8 name, or alias.
+func (R *EdgeColorRegistry) Resolve(name string) (EdgeColor, error) {
+	color, found := R.names[name]
+	if !found {
+		return 0, errors.Wrapf(ErrColorNotFound, "no EdgeColor named %q", name)
+	}
+	return EdgeColor(color), nil
+}
+
+// Name returns the ASCII name of the given color, or "" if the color is not registered.
+func (R *EdgeColorRegistry) Name(color EdgeColor) string {
+	return R.defs[color].NameAscii
+}
+
+// Defs returns all registered EdgeColorDefs, ordered by EdgeColor.
+func (R *EdgeColorRegistry) Defs() []EdgeColorDef {
+	defs := make([]EdgeColorDef, 0, len(R.defs))
+	for _, def := range R.defs {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].EdgeColor < defs[j].EdgeColor
+	})
+	return defs
+}
+
+// colorNames maps a color name (or alias) to a color value.
+type colorNames map[string]int64
+
+// add adds the names of a color def, checking against both itself and names already committed.
+// A def may repeat its own name (e.g. NameUTF8 == NameAscii).
+func (pending colorNames) add(committed colorNames, color int64, nameAscii, nameUTF8 string, aliases []string) error {
+	if nameAscii == "" {
+		return errors.Wrapf(ErrColorConflict, "color %d has no ASCII name", color)
+	}
+
+	names := make([]string, 0, 2+len(aliases))
+	names = append(names, nameAscii)
+	if nameUTF8 != "" {
+		names = append(names, nameUTF8)
+	}
+	names = append(names, aliases...)
+
+	for _, name := range names {
+		if name == "" {
+			return errors.Wrapf(ErrColorConflict, "color %q has an empty alias", nameAscii)
+		}
+		if existing, taken := committed[name]; taken {
+			return errors.Wrapf(ErrColorConflict, "name %q already refers to color %d", name, existing)
+		}
+		if existing, taken := pending[name]; taken && existing != color {
+			return errors.Wrapf(ErrColorConflict, "name %q already refers to color %d", name, existing)
+		}
+		pending[name] = color
+	}
+	return nil
+}
+
+// ColorRegistries returns the registries attached to the canonizer (see CanonizerOpts), allowing clients to register
+// and use symbolic color names.
+func ColorRegistries(canonizer IGraphCanonizer) (*VtxColorRegistry, *EdgeColorRegistry, error) {
+	ctx, err := encoderFor(canonizer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ctx.colorDefs, ctx.edgeDefs, nil
+}
@@ -0,0 +1,54 @@
+package orca
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestColorRegistry(t *testing.T) {
+	vtxColors := NewVtxColorRegistry()
+	err := vtxColors.AddVtxColors([]VtxColorDef{
+		{NameAscii: "C", Aliases: []string{"carbon"}, VtxColor: 6},
+		{NameAscii: "O", NameUTF8: "O", Aliases: []string{"oxygen"}, VtxColor: 8},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Conflicting colors or names must be rejected as a whole
+	err = vtxColors.AddVtxColors([]VtxColorDef{
+		{NameAscii: "N", VtxColor: 7},
+		{NameAscii: "Ox", Aliases: []string{"oxygen"}, VtxColor: 9},
+	})
+	if errors.Cause(err) != ErrColorConflict {
+		t.Fatalf("expected ErrColorConflict, got %v", err)
+	}
+	if _, err = vtxColors.Resolve("N"); errors.Cause(err) != ErrColorNotFound {
+		t.Fatalf("failed add should not register any defs, got %v", err)
+	}
+	if err = vtxColors.AddVtxColors([]VtxColorDef{{NameAscii: "X", VtxColor: 6}}); errors.Cause(err) != ErrColorConflict {
+		t.Fatalf("expected ErrColorConflict, got %v", err)
+	}
+
+	ctx := NewCanonizer(CanonizerOpts{
+		VtxColors: vtxColors,
+	})
+	ctxVtxColors, ctxEdgeColors, err := ColorRegistries(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ctxEdgeColors.AddEdgeColors([]EdgeColorDef{{NameAscii: "single", Aliases: []string{"-"}, EdgeColor: 1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if color, _ := ctxVtxColors.Resolve("oxygen"); color != 8 {
+		t.Fatalf("expected 8, got %v", color)
+	}
+	if color, _ := ctxEdgeColors.Resolve("-"); color != 1 {
+		t.Fatalf("expected 1, got %v", color)
+	}
+	if name := ctxVtxColors.Name(6); name != "C" {
+		t.Fatalf("expected C, got %q", name)
+	}
+}
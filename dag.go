@@ -292,7 +292,8 @@ func (ctx *encoderCtx) resetDag(dag *dag, rootVtx VtxLabel) {
 // }
 
 
-func (ctx *encoderCtx) ExportCanonic(subGraph *subGraph, rootVtx VtxLabel, Gout GraphOut) {
+// ExportCanonic emits the canonized dag for the given root, offsetting each canonic VtxLabel by labelOffset.
+func (ctx *encoderCtx) ExportCanonic(subGraph *subGraph, rootVtx VtxLabel, labelOffset VtxLabel, Gout GraphOut) {
     dag := ctx.dagForRootVtx(subGraph, rootVtx)
     
 	for !dag.canonicComplete {
@@ -300,7 +301,7 @@ func (ctx *encoderCtx) ExportCanonic(subGraph *subGraph, rootVtx VtxLabel, Gout
 	}
 
     for i, vi := range dag.vtx {
-        canonicFrom := VtxLabel(i+1) 
+        canonicFrom := labelOffset + VtxLabel(i+1) 
 
         Gout.Vtx <- Vtx{
             Label: canonicFrom,
@@ -311,7 +312,7 @@ func (ctx *encoderCtx) ExportCanonic(subGraph *subGraph, rootVtx VtxLabel, Gout
 			if edge.edgeType == dagEdgeIn || edge.edgeType == dagEdgeCo {
 
                 // Add one to vtx index for one-based indexing (i.e. VtxLabel convention)
-				canonicTo := VtxLabel(dag.vtxIndex[edge.toVtx]+1)
+				canonicTo := labelOffset + VtxLabel(dag.vtxIndex[edge.toVtx]+1)
 
 				// Skip edges that go ahead or else we'll get a duplicate for each cobound edge.
 				if canonicTo < canonicFrom {
@@ -324,7 +325,6 @@ func (ctx *encoderCtx) ExportCanonic(subGraph *subGraph, rootVtx VtxLabel, Gout
 			}
         }
     }
-    Gout.Break()
 }
 
 
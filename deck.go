@@ -0,0 +1,80 @@
+package orca
+
+// DeckOpts controls CanonizeDeck.
+type DeckOpts struct {
+	Vertices bool // also canonize each vertex-deleted subgraph
+}
+
+// Deck holds the canonical forms of a graph's single-edge-deleted subgraphs (its edge deck) and optionally its
+// single-vertex-deleted subgraphs (its vertex deck).  Cards are in the order of the graph's edges and vertices, so
+// Edges[i] is the graph without G.Edges[i] and Vertices[i] is the graph without G.Vtx[i] (and its edges).
+type Deck struct {
+	Whole    *Canonic
+	Edges    []*Canonic
+	Vertices []*Canonic
+}
+
+// CanonizeDeck returns the deck of G.  Each card is an edit of G on the same canonizer (see ApplyEdits) that is
+// undone before the next, so the subgraph catalog built up by earlier cards is shared with later ones.
+func CanonizeDeck(canonizer IGraphCanonizer, G *Graph, opts DeckOpts) (*Deck, error) {
+	whole, err := CanonizeGraph(canonizer, G)
+	if err != nil {
+		return nil, err
+	}
+	D := &Deck{
+		Whole: whole,
+		Edges: make([]*Canonic, len(G.Edges)),
+	}
+
+	for i, e := range G.Edges {
+		D.Edges[i], err = canonizeCard(canonizer, G,
+			[]GraphEdit{{Op: EditRemoveEdge, Edge: e}},
+			[]GraphEdit{{Op: EditAddEdge, Edge: e}},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Vertices {
+		D.Vertices = make([]*Canonic, len(G.Vtx))
+		for i, v := range G.Vtx {
+			restore := []GraphEdit{{Op: EditAddVtx, Vtx: v}}
+			for _, e := range G.Edges {
+				if e.Va == v.Label || e.Vb == v.Label {
+					restore = append(restore, GraphEdit{Op: EditAddEdge, Edge: e})
+				}
+			}
+			D.Vertices[i], err = canonizeCard(canonizer, G,
+				[]GraphEdit{{Op: EditRemoveVtx, Vtx: v}},
+				restore,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return D, nil
+}
+
+// canonizeCard returns the canonical form of G with the given edits, which are then undone via restore.
+func canonizeCard(canonizer IGraphCanonizer, G *Graph, edits, restore []GraphEdit) (*Canonic, error) {
+	card := &Graph{
+		Vtx:   G.Vtx,
+		Edges: G.Edges,
+	}
+	if err := card.ApplyEdits(edits...); err != nil {
+		return nil, err
+	}
+	if err := ApplyEdits(canonizer, edits...); err != nil {
+		return nil, err
+	}
+	labeling, err := CanonicLabeling(canonizer)
+	if err != nil {
+		return nil, err
+	}
+	if err = ApplyEdits(canonizer, restore...); err != nil {
+		return nil, err
+	}
+	return relabel(card, labeling), nil
+}
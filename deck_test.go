@@ -0,0 +1,51 @@
+package orca
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCanonizeDeck(t *testing.T) {
+	// A path 1-2-3-4 with a pendant 5 on 2
+	G, err := ParseGraphText("1-2 2-3 3-4 2-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	D, err := CanonizeDeck(NewCanonizer(DefaultCanonizerOpts), G, DeckOpts{Vertices: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(D.Edges) != 4 || len(D.Vertices) != 5 {
+		t.Fatalf("expected 4 edge cards and 5 vertex cards, got %d and %d", len(D.Edges), len(D.Vertices))
+	}
+
+	expectCard := func(card *Canonic, text string) {
+		expected, err := ParseGraphText(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		C, err := CanonizeGraph(NewCanonizer(DefaultCanonizerOpts), expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(card.Encoding(), C.Encoding()) {
+			t.Fatalf("expected card %v, got %v", C.Graph.String(), card.Graph.String())
+		}
+	}
+
+	// Removing 1-2 and 2-5 both leave a path of 4 plus an isolated vertex
+	expectCard(D.Edges[0], "1-2 2-3 3-4 v5")
+	expectCard(D.Edges[3], "1-2 2-3 3-4 v5")
+	expectCard(D.Edges[1], "1-2 2-3 v4 v5 4-5")
+	expectCard(D.Edges[2], "1-2 2-3 2-4 v5")
+
+	// Removing vertex 2 leaves three isolated vertices and an edge
+	expectCard(D.Vertices[1], "1-2 v3 v4")
+	expectCard(D.Vertices[3], "1-2 2-3 2-4")
+	if !bytes.Equal(D.Vertices[0].Encoding(), D.Vertices[4].Encoding()) {
+		t.Fatal("expected the two pendant vertices to give the same card")
+	}
+
+	// The whole graph is still intact in the canonizer's view
+	expectCard(D.Whole, "1-2 2-3 3-4 2-5")
+}
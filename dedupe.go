@@ -0,0 +1,437 @@
+package orca
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+/* Deduplication:
+
+A Deduper assigns each graph it is given to an isomorphism class, keyed by the SHA-256 of the graph's canonical
+encoding.  Classes are numbered 0, 1, 2.. in order of first occurrence.
+
+The seen set (key to class) is held in memory until it reaches DedupeOpts.MaxMemClasses, at which point it is
+written to a sorted run file and cleared.  Lookups then binary search each run, and runs are merged once there are
+too many of them.  Per-class data lives on disk from the start:
+
+    classes     fixed records of [first id offset, count, last id offset], indexed by class
+    ids         records of [next id offset, len, id], where each class's ids form a chain from first to last
+
+Appending an id to a class patches the next offset of the class's last id (0 ends a chain, since the first record
+of the file never follows another).  Memory use is bounded by MaxMemClasses regardless of how many graphs or classes
+there are, and the ids of a class are streamed from disk (see Deduper.IDs) rather than loaded at once.
+*/
+
+// DedupeOpts controls a Deduper.
+type DedupeOpts struct {
+	MaxMemClasses int    // seen set entries held in memory before spilling to disk (default 1<<20)
+	TempDir       string // where spill files are placed (default os.TempDir())
+}
+
+// DedupeResult describes the class a graph was assigned to.
+type DedupeResult struct {
+	Class   uint64 // class index, in order of first occurrence
+	First   bool   // true if this graph is the first of its class
+	FirstID string // the id of the first graph of this class
+}
+
+// DedupeClass summarizes an isomorphism class.
+type DedupeClass struct {
+	Class   uint64
+	Count   uint64 // number of graphs in the class (including the first)
+	FirstID string // id of the first graph of the class
+}
+
+type dedupeKey [sha256.Size]byte
+
+const (
+	dedupeRunRecSize   = sha256.Size + 8
+	dedupeClassRecSize = 24
+	dedupeMaxRuns      = 8
+)
+
+// Deduper tracks the isomorphism classes of a stream of graphs.  Close must be called to remove its spill files.
+type Deduper struct {
+	canonizer IGraphCanonizer
+	opts      DedupeOpts
+	dir       string
+	seen      map[dedupeKey]uint64
+	runs      []*os.File
+	runSeq    int
+	classes   *os.File
+	ids       *os.File
+	idsLen    int64
+	numGraphs uint64
+	numClass  uint64
+	buf       []byte
+}
+
+// NewDeduper returns a Deduper using the given canonizer (which may be nil if only AddEncoding is used).
+func NewDeduper(canonizer IGraphCanonizer, opts DedupeOpts) (*Deduper, error) {
+	if opts.MaxMemClasses <= 0 {
+		opts.MaxMemClasses = 1 << 20
+	}
+	dir, err := os.MkdirTemp(opts.TempDir, "orca-dedupe-")
+	if err != nil {
+		return nil, err
+	}
+	D := &Deduper{
+		canonizer: canonizer,
+		opts:      opts,
+		dir:       dir,
+		seen:      make(map[dedupeKey]uint64),
+	}
+	if D.classes, err = os.Create(filepath.Join(dir, "classes")); err == nil {
+		D.ids, err = os.Create(filepath.Join(dir, "ids"))
+	}
+	if err != nil {
+		D.Close()
+		return nil, err
+	}
+	return D, nil
+}
+
+// Close releases and removes all spill files.
+func (D *Deduper) Close() error {
+	for _, run := range D.runs {
+		run.Close()
+	}
+	D.runs = nil
+	if D.classes != nil {
+		D.classes.Close()
+	}
+	if D.ids != nil {
+		D.ids.Close()
+	}
+	return os.RemoveAll(D.dir)
+}
+
+// NumGraphs returns the number of graphs added.
+func (D *Deduper) NumGraphs() uint64 {
+	return D.numGraphs
+}
+
+// NumClasses returns the number of isomorphism classes seen.
+func (D *Deduper) NumClasses() uint64 {
+	return D.numClass
+}
+
+// Add canonizes G and assigns it to its isomorphism class.
+func (D *Deduper) Add(id string, G *Graph) (DedupeResult, error) {
+	if D.canonizer == nil {
+		return DedupeResult{}, errors.New("Deduper has no canonizer")
+	}
+	C, err := CanonizeGraph(D.canonizer, G)
+	if err != nil {
+		return DedupeResult{}, err
+	}
+	return D.AddEncoding(id, C.Encoding())
+}
+
+// AddEncoding assigns a graph, given by its canonical encoding, to its isomorphism class.
+func (D *Deduper) AddEncoding(id string, Genc GraphEncoding) (DedupeResult, error) {
+	key := dedupeKey(sha256.Sum256(Genc))
+	class, found, err := D.lookup(key)
+	if err != nil {
+		return DedupeResult{}, err
+	}
+
+	res := DedupeResult{
+		Class: class,
+		First: !found,
+	}
+	D.numGraphs++
+
+	if !found {
+		res.Class = D.numClass
+		res.FirstID = id
+		idOffset, err := D.appendID(id)
+		if err != nil {
+			return res, err
+		}
+		D.numClass++
+		if err = D.writeClassRec(res.Class, [3]uint64{uint64(idOffset), 1, uint64(idOffset)}); err != nil {
+			return res, err
+		}
+		D.seen[key] = res.Class
+		if len(D.seen) >= D.opts.MaxMemClasses {
+			err = D.spill()
+		}
+		return res, err
+	}
+
+	rec, err := D.readClassRec(class)
+	if err != nil {
+		return res, err
+	}
+	idOffset, err := D.appendID(id)
+	if err == nil {
+		err = D.writeNextID(int64(rec[2]), idOffset)
+	}
+	if err != nil {
+		return res, err
+	}
+	rec[1]++
+	rec[2] = uint64(idOffset)
+	if err = D.writeClassRec(class, rec); err != nil {
+		return res, err
+	}
+	res.FirstID, _, err = D.readID(int64(rec[0]))
+	return res, err
+}
+
+// Classes calls fn for each class in class order.
+func (D *Deduper) Classes(fn func(class DedupeClass) error) error {
+	for ci := uint64(0); ci < D.numClass; ci++ {
+		rec, err := D.readClassRec(ci)
+		if err != nil {
+			return err
+		}
+		class := DedupeClass{
+			Class: ci,
+			Count: rec[1],
+		}
+		if class.FirstID, _, err = D.readID(int64(rec[0])); err != nil {
+			return err
+		}
+		if err = fn(class); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IDs calls fn with the id of each graph of the given class (starting with its first), in order of occurrence.
+func (D *Deduper) IDs(class uint64, fn func(id string) error) error {
+	if class >= D.numClass {
+		return errors.Errorf("class %d not found", class)
+	}
+	rec, err := D.readClassRec(class)
+	if err != nil {
+		return err
+	}
+	offset := int64(rec[0])
+	for i := uint64(0); i < rec[1]; i++ {
+		var id string
+		if id, offset, err = D.readID(offset); err != nil {
+			return err
+		}
+		if err = fn(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (D *Deduper) readClassRec(class uint64) ([3]uint64, error) {
+	var rec [3]uint64
+	var buf [dedupeClassRecSize]byte
+	if _, err := D.classes.ReadAt(buf[:], int64(class)*dedupeClassRecSize); err != nil {
+		return rec, err
+	}
+	for i := range rec {
+		rec[i] = binary.LittleEndian.Uint64(buf[8*i:])
+	}
+	return rec, nil
+}
+
+func (D *Deduper) writeClassRec(class uint64, rec [3]uint64) error {
+	var buf [dedupeClassRecSize]byte
+	for i := range rec {
+		binary.LittleEndian.PutUint64(buf[8*i:], rec[i])
+	}
+	_, err := D.classes.WriteAt(buf[:], int64(class)*dedupeClassRecSize)
+	return err
+}
+
+// appendID appends an id record that ends its class's chain, returning its offset.
+func (D *Deduper) appendID(id string) (int64, error) {
+	D.buf = append(D.buf[:0], make([]byte, 8+binary.MaxVarintLen64)...)
+	n := binary.PutUvarint(D.buf[8:], uint64(len(id)))
+	D.buf = append(D.buf[:8+n], id...)
+
+	offset := D.idsLen
+	if _, err := D.ids.WriteAt(D.buf, offset); err != nil {
+		return 0, err
+	}
+	D.idsLen += int64(len(D.buf))
+	return offset, nil
+}
+
+// writeNextID chains the id record at offset to the one at next.
+func (D *Deduper) writeNextID(offset, next int64) error {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(next))
+	_, err := D.ids.WriteAt(buf[:], offset)
+	return err
+}
+
+// readID returns the id at the given offset along with the offset of the next id in its chain.
+func (D *Deduper) readID(offset int64) (string, int64, error) {
+	r := bufio.NewReaderSize(io.NewSectionReader(D.ids, offset, D.idsLen-offset), 64)
+	var next [8]byte
+	if _, err := io.ReadFull(r, next[:]); err != nil {
+		return "", 0, err
+	}
+	idLen, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", 0, err
+	}
+	id := make([]byte, idLen)
+	if _, err = io.ReadFull(r, id); err != nil {
+		return "", 0, err
+	}
+	return string(id), int64(binary.LittleEndian.Uint64(next[:])), nil
+}
+
+func (D *Deduper) lookup(key dedupeKey) (uint64, bool, error) {
+	if class, found := D.seen[key]; found {
+		return class, true, nil
+	}
+	var rec [dedupeRunRecSize]byte
+	for _, run := range D.runs {
+		info, err := run.Stat()
+		if err != nil {
+			return 0, false, err
+		}
+		n := int(info.Size() / dedupeRunRecSize)
+
+		var readErr error
+		i := sort.Search(n, func(i int) bool {
+			if _, err := run.ReadAt(rec[:], int64(i)*dedupeRunRecSize); err != nil {
+				readErr = err
+				return true
+			}
+			return bytes.Compare(rec[:sha256.Size], key[:]) >= 0
+		})
+		if readErr != nil {
+			return 0, false, readErr
+		}
+		if i < n {
+			if _, err := run.ReadAt(rec[:], int64(i)*dedupeRunRecSize); err != nil {
+				return 0, false, err
+			}
+			if bytes.Equal(rec[:sha256.Size], key[:]) {
+				return binary.LittleEndian.Uint64(rec[sha256.Size:]), true, nil
+			}
+		}
+	}
+	return 0, false, nil
+}
+
+func (D *Deduper) newRun() (*os.File, error) {
+	D.runSeq++
+	return os.Create(filepath.Join(D.dir, "run"+strconv.Itoa(D.runSeq)))
+}
+
+// spill writes the in-memory seen set to a new sorted run, merging all runs into one if there are too many.
+func (D *Deduper) spill() error {
+	keys := make([]dedupeKey, 0, len(D.seen))
+	for key := range D.seen {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i][:], keys[j][:]) < 0
+	})
+
+	run, err := D.newRun()
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(run)
+	var rec [dedupeRunRecSize]byte
+	for _, key := range keys {
+		copy(rec[:], key[:])
+		binary.LittleEndian.PutUint64(rec[sha256.Size:], D.seen[key])
+		out.Write(rec[:])
+	}
+	if err = out.Flush(); err != nil {
+		run.Close()
+		return err
+	}
+	D.runs = append(D.runs, run)
+	D.seen = make(map[dedupeKey]uint64)
+
+	if len(D.runs) > dedupeMaxRuns {
+		return D.mergeRuns()
+	}
+	return nil
+}
+
+// dedupeRunHeap orders run readers by their current record.
+type dedupeRunHeap []*dedupeRunReader
+
+type dedupeRunReader struct {
+	r   *bufio.Reader
+	rec [dedupeRunRecSize]byte
+}
+
+func (h dedupeRunHeap) Len() int            { return len(h) }
+func (h dedupeRunHeap) Less(i, j int) bool  { return bytes.Compare(h[i].rec[:], h[j].rec[:]) < 0 }
+func (h dedupeRunHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *dedupeRunHeap) Push(x interface{}) { *h = append(*h, x.(*dedupeRunReader)) }
+func (h *dedupeRunHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// mergeRuns merges all runs into a single run.
+func (D *Deduper) mergeRuns() error {
+	merged, err := D.newRun()
+	if err != nil {
+		return err
+	}
+
+	h := dedupeRunHeap{}
+	for _, run := range D.runs {
+		R := &dedupeRunReader{
+			r: bufio.NewReader(io.NewSectionReader(run, 0, 1<<62)),
+		}
+		_, err := io.ReadFull(R.r, R.rec[:])
+		if err == nil {
+			h = append(h, R)
+		} else if err != io.EOF {
+			merged.Close()
+			return err
+		}
+	}
+	heap.Init(&h)
+
+	out := bufio.NewWriter(merged)
+	for len(h) > 0 {
+		R := h[0]
+		out.Write(R.rec[:])
+		_, err := io.ReadFull(R.r, R.rec[:])
+		if err == nil {
+			heap.Fix(&h, 0)
+		} else if err == io.EOF {
+			heap.Pop(&h)
+		} else {
+			merged.Close()
+			return err
+		}
+	}
+	if err = out.Flush(); err != nil {
+		merged.Close()
+		return err
+	}
+
+	for _, run := range D.runs {
+		run.Close()
+		os.Remove(run.Name())
+	}
+	D.runs = append(D.runs[:0], merged)
+	return nil
+}
@@ -0,0 +1,68 @@
+package orca
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDeduper(t *testing.T) {
+	// Small enough to force several spills and a merge of runs
+	D, err := NewDeduper(NewCanonizer(DefaultCanonizerOpts), DedupeOpts{
+		MaxMemClasses: 2,
+		TempDir:       t.TempDir(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer D.Close()
+
+	// Paths 1..24, each listed twice (with the second relabeled), followed by a third copy of the first path
+	var texts []string
+	for n := 2; n <= 25; n++ {
+		fwd, rev := "", ""
+		for i := 1; i < n; i++ {
+			fwd += fmt.Sprintf("%d-%d ", i, i+1)
+			rev += fmt.Sprintf("%d-%d ", n-i+1, n-i)
+		}
+		texts = append(texts, fwd, rev)
+	}
+	texts = append(texts, "2-1")
+
+	for i, text := range texts {
+		G, err := ParseGraphText(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := D.Add(fmt.Sprint("g", i), G)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.First != (i%2 == 0 && i < 48) || res.FirstID != fmt.Sprint("g", i&^1%48) {
+			t.Fatalf("graph %d: unexpected result %+v", i, res)
+		}
+	}
+	if D.NumGraphs() != 49 || D.NumClasses() != 24 {
+		t.Fatalf("unexpected counts %d, %d", D.NumGraphs(), D.NumClasses())
+	}
+
+	err = D.Classes(func(class DedupeClass) error {
+		expected := []string{fmt.Sprint("g", 2*class.Class), fmt.Sprint("g", 2*class.Class+1)}
+		if class.Class == 0 {
+			expected = append(expected, "g48")
+		}
+		var ids []string
+		if err := D.IDs(class.Class, func(id string) error {
+			ids = append(ids, id)
+			return nil
+		}); err != nil {
+			return err
+		}
+		if class.FirstID != expected[0] || class.Count != uint64(len(expected)) || fmt.Sprint(ids) != fmt.Sprint(expected) {
+			t.Fatalf("unexpected class %+v with ids %v", class, ids)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,182 @@
+package orca
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+/* DIMACS:
+
+    c <comment>
+    p edge <num vertices> <num edges>
+    n <vertex> <color>
+    e <vertex> <vertex> [<edge color>]
+
+Vertices are VtxLabels 1..N.  "n" lines (as used by bliss and the graph isomorphism benchmark sets) assign a vertex
+color, with unlisted vertices keeping the default color (0).  The optional third field of an "e" line is a go-orca
+extension for edge colors.  Since benchmark files often list an edge in both directions, repeated edges are ignored.
+*/
+
+var ErrBadDIMACS = errors.New("bad DIMACS data")
+
+// ReadDIMACS reads a graph in DIMACS "p edge" format.
+func ReadDIMACS(r io.Reader) (*Graph, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var G *Graph
+	var seen map[CanonicalEdge]bool
+	lineNum := 0
+	errorf := func(format string, args ...interface{}) error {
+		return errors.Wrapf(ErrBadDIMACS, "line %d: %s", lineNum, fmt.Sprintf(format, args...))
+	}
+	vtxArg := func(field string) (VtxLabel, error) {
+		vi, err := strconv.ParseUint(field, 10, 32)
+		if err != nil || vi < 1 || int(vi) > len(G.Vtx) {
+			return 0, errorf("invalid vertex %q", field)
+		}
+		return VtxLabel(vi), nil
+	}
+
+	for scanner.Scan() {
+		lineNum++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		if G == nil && fields[0] != "p" {
+			return nil, errorf("expected problem line")
+		}
+
+		switch fields[0] {
+		case "p":
+			if G != nil {
+				return nil, errorf("multiple problem lines")
+			}
+			if len(fields) != 4 || (fields[1] != "edge" && fields[1] != "col") {
+				return nil, errorf("expected \"p edge <N> <M>\"")
+			}
+			Nv, err1 := strconv.ParseUint(fields[2], 10, 31)
+			Ne, err2 := strconv.ParseUint(fields[3], 10, 31)
+			if err1 != nil || err2 != nil {
+				return nil, errorf("bad vertex or edge count")
+			}
+			if Nv > uint64(MaxParsedVtx) {
+				return nil, errorf("vertex count %d exceeds MaxParsedVtx", Nv)
+			}
+			G = &Graph{
+				Vtx:   make([]Vtx, Nv),
+				Edges: make([]Edge, 0, min(int(Ne), 1<<20)),
+			}
+			for i := range G.Vtx {
+				G.Vtx[i].Label = VtxLabel(i + 1)
+			}
+			seen = make(map[CanonicalEdge]bool, cap(G.Edges))
+
+		case "n":
+			if len(fields) != 3 {
+				return nil, errorf("expected \"n <vertex> <color>\"")
+			}
+			vi, err := vtxArg(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			color, err := strconv.ParseInt(fields[2], 10, 64)
+			if err != nil || color < 0 {
+				return nil, errorf("invalid vertex color %q", fields[2])
+			}
+			G.Vtx[vi-1].Color = VtxColor(color)
+
+		case "e":
+			if len(fields) != 3 && len(fields) != 4 {
+				return nil, errorf("expected \"e <vertex> <vertex>\"")
+			}
+			e := Edge{}
+			var err error
+			if e.Va, err = vtxArg(fields[1]); err != nil {
+				return nil, err
+			}
+			if e.Vb, err = vtxArg(fields[2]); err != nil {
+				return nil, err
+			}
+			if e.Va == e.Vb {
+				return nil, errorf("loop at vertex %d is not supported", e.Va)
+			}
+			if len(fields) == 4 {
+				color, err := strconv.ParseInt(fields[3], 10, 64)
+				if err != nil {
+					return nil, errorf("invalid edge color %q", fields[3])
+				}
+				e.Color = EdgeColor(color)
+			}
+			if key := e.FormCanonicalEdge(); !seen[key] {
+				seen[key] = true
+				G.Edges = append(G.Edges, e)
+			}
+
+		default:
+			return nil, errorf("unknown line type %q", fields[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if G == nil {
+		return nil, errors.Wrap(ErrBadDIMACS, "missing problem line")
+	}
+	return G, nil
+}
+
+// ExportDIMACS reads a DIMACS graph and sends it to Gout.  If an error is returned, nothing is sent.
+func ExportDIMACS(r io.Reader, Gout GraphOut) error {
+	G, err := ReadDIMACS(r)
+	if err != nil {
+		return err
+	}
+	G.Export(Gout)
+	return nil
+}
+
+// WriteDIMACS writes G in DIMACS format, numbering vertices 1..N in VtxLabel order.
+func WriteDIMACS(w io.Writer, G *Graph) error {
+	vtx := append([]Vtx(nil), G.Vtx...)
+	sort.Slice(vtx, func(i, j int) bool {
+		return vtx[i].Label < vtx[j].Label
+	})
+	idxOf := make(map[VtxLabel]int, len(vtx))
+	for i, v := range vtx {
+		idxOf[v.Label] = i + 1
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "p edge %d %d\n", len(vtx), len(G.Edges))
+	for i, v := range vtx {
+		if v.Color != 0 {
+			fmt.Fprintf(out, "n %d %d\n", i+1, v.Color)
+		}
+	}
+	for _, e := range G.Edges {
+		a, foundA := idxOf[e.Va]
+		b, foundB := idxOf[e.Vb]
+		if !foundA || !foundB {
+			return errors.Errorf("edge %d-%d references a missing vertex", e.Va, e.Vb)
+		}
+		if e.Color != 0 {
+			fmt.Fprintf(out, "e %d %d %d\n", a, b, e.Color)
+		} else {
+			fmt.Fprintf(out, "e %d %d\n", a, b)
+		}
+	}
+	return out.Flush()
+}
+
+// WriteDIMACSFrom consumes a graph from Gin and writes it in DIMACS format.
+func WriteDIMACSFrom(w io.Writer, Gin GraphIn) error {
+	return WriteDIMACS(w, ReadGraph(Gin))
+}
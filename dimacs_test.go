@@ -0,0 +1,41 @@
+package orca
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDIMACS(t *testing.T) {
+	G, err := ReadDIMACS(strings.NewReader(`c a colored path
+p edge 4 3
+n 1 2
+n 4 2
+e 1 2
+e 2 1
+e 2 3
+e 3 4 7
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(G.Vtx) != 4 || len(G.Edges) != 3 || G.Vtx[3].Color != 2 || G.Edges[2].Color != 7 {
+		t.Fatalf("unexpected graph %v", G)
+	}
+
+	buf := &bytes.Buffer{}
+	if err = WriteDIMACS(buf, G); err != nil {
+		t.Fatal(err)
+	}
+	expected := "p edge 4 3\nn 1 2\nn 4 2\ne 1 2\ne 2 3\ne 3 4 7\n"
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	if _, err = ReadDIMACS(strings.NewReader("p edge 2 1\ne 1 3\n")); err == nil {
+		t.Fatal("expected invalid vertex error")
+	}
+	if _, err = ReadDIMACS(strings.NewReader("p edge 2147483647 0\n")); err == nil {
+		t.Fatal("expected oversized vertex count to be rejected")
+	}
+}
@@ -0,0 +1,143 @@
+package orca
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// DOTOpts controls how graphs are rendered in Graphviz DOT.
+type DOTOpts struct {
+	Name       string             // graph name (defaults to "G")
+	VtxColors  *VtxColorRegistry  // if set, registered VtxColors are shown by name
+	EdgeColors *EdgeColorRegistry // if set, registered EdgeColors are shown by name
+}
+
+// dotPalette is cycled through to give each VtxColor (and EdgeColor) a distinct look.
+var dotPalette = []string{
+	"#8dd3c7", "#ffffb3", "#bebada", "#fb8072", "#80b1d3", "#fdb462",
+	"#b3de69", "#fccde5", "#d9d9d9", "#bc80bd", "#ccebc5", "#ffed6f",
+}
+
+func dotPaletteColor(color int64) string {
+	idx := color % int64(len(dotPalette))
+	if idx < 0 {
+		idx += int64(len(dotPalette))
+	}
+	return dotPalette[idx]
+}
+
+func (opts *DOTOpts) vtxColorName(color VtxColor) string {
+	if opts.VtxColors != nil {
+		if name := opts.VtxColors.Name(color); name != "" {
+			return name
+		}
+	}
+	return fmt.Sprint(int64(color))
+}
+
+func (opts *DOTOpts) edgeColorName(color EdgeColor) string {
+	if opts.EdgeColors != nil {
+		if name := opts.EdgeColors.Name(color); name != "" {
+			return name
+		}
+	}
+	return fmt.Sprint(int64(color))
+}
+
+func (opts *DOTOpts) graphName() string {
+	if opts.Name == "" {
+		return "G"
+	}
+	return opts.Name
+}
+
+// WriteDOT writes G (typically a canonic graph) as an undirected Graphviz graph, where each vertex is labeled
+// "<VtxLabel>: <VtxColor>" and filled according to its color, and each edge is labeled with its (non-default) color.
+func WriteDOT(w io.Writer, G *Graph, opts DOTOpts) error {
+	vtx := append([]Vtx(nil), G.Vtx...)
+	sort.Slice(vtx, func(i, j int) bool {
+		return vtx[i].Label < vtx[j].Label
+	})
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "graph %q {\n", opts.graphName())
+	fmt.Fprintf(out, "  node [shape=circle, style=filled];\n")
+	for _, v := range vtx {
+		fmt.Fprintf(out, "  v%d [label=%q, fillcolor=%q];\n", v.Label, fmt.Sprintf("%d: %s", v.Label, opts.vtxColorName(v.Color)), dotPaletteColor(int64(v.Color)))
+	}
+	for _, e := range G.Edges {
+		fmt.Fprintf(out, "  v%d -- v%d", e.Va, e.Vb)
+		if e.Color != 0 {
+			fmt.Fprintf(out, " [label=%q, color=%q, penwidth=2]", opts.edgeColorName(e.Color), dotPaletteColor(int64(e.Color)))
+		}
+		out.WriteString(";\n")
+	}
+	out.WriteString("}\n")
+	return out.Flush()
+}
+
+// WriteDagDOT writes the (fully canonized) dag rooted at the given vertex of the graph the canonizer most recently
+// built in Graphviz DOT, where each depth is drawn as a rank.  This is intended for debugging canonization differences.
+func WriteDagDOT(w io.Writer, canonizer IGraphCanonizer, rootVtx VtxLabel, opts DOTOpts) error {
+	ctx, err := encoderFor(canonizer)
+	if err != nil {
+		return err
+	}
+	return ctx.WriteDagDOT(w, rootVtx, opts)
+}
+
+// WriteDagDOT -- see WriteDagDOT()
+func (ctx *encoderCtx) WriteDagDOT(w io.Writer, rootVtx VtxLabel, opts DOTOpts) error {
+	if ctx.Error() != nil {
+		return ctx.Error()
+	}
+	if _, exists := ctx.vtxIndex[rootVtx]; !exists {
+		return errors.Errorf("vertex %d not found", rootVtx)
+	}
+
+	subG := ctx.canonize()
+	dag := ctx.dagForRootVtx(subG, rootVtx)
+	for !dag.canonicComplete {
+		ctx.canonizeNextDepth(subG, dag)
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "digraph %q {\n", opts.graphName())
+	fmt.Fprintf(out, "  rankdir=TB;\n  node [shape=circle, style=filled];\n")
+
+	// Each depth forms a rank, with each dagVtx labeled by its VtxLabel, VtxColor, and canonic position in the dag
+	L := uint32(0)
+	for depth, R := range dag.depthPos {
+		fmt.Fprintf(out, "  subgraph depth%d {\n    rank=same;\n", depth)
+		for i := L; i < R; i++ {
+			vi := dag.vtx[i]
+			label := fmt.Sprintf("%d: %s\n#%d", vi.VtxLabel, opts.vtxColorName(vi.VtxColor), i+1)
+			fmt.Fprintf(out, "    v%d [label=%q, fillcolor=%q];\n", vi.VtxLabel, label, dotPaletteColor(int64(vi.VtxColor)))
+		}
+		out.WriteString("  }\n")
+		L = R
+	}
+
+	// dagEdgeOut: parent to child (solid), dagEdgeIn: child back to parent (dotted), dagEdgeCo: within a depth (dashed)
+	for i, vi := range dag.vtx {
+		for _, edge := range vi.edges {
+			label := opts.edgeColorName(edge.edgeColor)
+			switch edge.edgeType {
+			case dagEdgeOut:
+				fmt.Fprintf(out, "  v%d -> v%d [label=%q, color=\"black\"];\n", vi.VtxLabel, edge.toVtx, label)
+			case dagEdgeIn:
+				fmt.Fprintf(out, "  v%d -> v%d [style=dotted, color=\"gray50\", constraint=false];\n", vi.VtxLabel, edge.toVtx)
+			case dagEdgeCo:
+				if dag.vtxIndex[edge.toVtx] > uint32(i) {
+					fmt.Fprintf(out, "  v%d -> v%d [label=%q, style=dashed, color=\"red\", dir=none, constraint=false];\n", vi.VtxLabel, edge.toVtx, label)
+				}
+			}
+		}
+	}
+	out.WriteString("}\n")
+	return out.Flush()
+}
@@ -0,0 +1,43 @@
+package orca
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDOT(t *testing.T) {
+	Gin, Gout := NewGraphIO()
+	go genHiggs(Gout)
+	G := ReadGraph(Gin)
+
+	ctx := NewCanonizer(DefaultCanonizerOpts)
+	C, err := CanonizeGraph(ctx, G)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err = WriteDOT(buf, &C.Graph, DOTOpts{}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(buf.String(), " -- ") != len(G.Edges) {
+		t.Fatalf("expected %d edges:\n%s", len(G.Edges), buf.String())
+	}
+
+	// The cube's dag from any vertex has 4 depths, and each of the 12 edges appears as a dagEdgeOut or dagEdgeCo
+	buf.Reset()
+	if err = WriteDagDOT(buf, ctx, 1, DOTOpts{}); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	if strings.Count(dot, "rank=same") != 4 {
+		t.Fatalf("expected 4 ranks:\n%s", dot)
+	}
+	if strings.Count(dot, "color=\"black\"")+strings.Count(dot, "style=dashed") != len(G.Edges) {
+		t.Fatalf("expected %d edges:\n%s", len(G.Edges), dot)
+	}
+	if err = WriteDagDOT(buf, ctx, 99, DOTOpts{}); err == nil {
+		t.Fatal("expected missing vertex error")
+	}
+}
@@ -0,0 +1,252 @@
+package orca
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+/* Fingerprints:
+
+A Fingerprint is a fixed-length bit vector where each feature of a graph sets one bit chosen by hashing it.  The
+features of PathFingerprint are the color sequences of the graph's simple paths and cycles:
+
+    path            vertex and edge colors along the path, read from whichever end gives the smaller sequence
+    cycle           vertex and edge colors around the cycle, from the rotation and direction giving the smallest sequence
+
+Since every path and cycle of a subgraph is also a path or cycle of any graph containing it, the fingerprint of a
+pattern is contained in the fingerprint of every graph it can be matched in (see MatchSubgraphs).  The converse does
+not hold, so a fingerprint is only a screen ahead of exact matching.
+
+The number of paths grows exponentially with MaxPathLen on dense graphs, so FingerprintOpts.MaxPaths bounds the walk.
+Past it, PathFingerprint returns ErrFingerprintBudget rather than a fingerprint missing features, since a partial
+fingerprint could wrongly screen out a graph containing the pattern.
+
+GraphFingerprint adds a third kind of feature, for comparing graphs by similarity (see similarity.go):
+
+    neighborhood    the canonical neighborhood of each vertex at each radius (see NeighborhoodHashes)
+
+A subgraph's vertices can have smaller neighborhoods than in a graph containing it, so these fingerprints can't
+screen substructure searches.
+*/
+
+// ErrFingerprintBudget means a graph has more paths than FingerprintOpts.MaxPaths allows.
+var ErrFingerprintBudget = errors.New("fingerprint path budget exceeded")
+
+// Fingerprint is a bit vector of features, 64 per word.
+type Fingerprint []uint64
+
+// FingerprintOpts controls which features a fingerprint contains.
+type FingerprintOpts struct {
+	NumBits     int // rounded up to a multiple of 64
+	MaxPathLen  int // longest path feature, in edges
+	MaxCycleLen int // longest cycle feature, in edges (0 for none)
+	Radius      int // largest neighborhood feature (used by GraphFingerprint)
+	MaxPaths    int // most paths walked (cycles included) before giving up (0 for no limit)
+}
+
+var DefaultFingerprintOpts = FingerprintOpts{
+	NumBits:     1024,
+	MaxPathLen:  6,
+	MaxCycleLen: 8,
+	Radius:      2,
+	MaxPaths:    1 << 18,
+}
+
+func newFingerprint(numBits int) Fingerprint {
+	if numBits < 64 {
+		numBits = 64
+	}
+	return make(Fingerprint, (numBits+63)/64)
+}
+
+func (fp Fingerprint) set(hash uint64) {
+	hash %= uint64(len(fp) * 64)
+	fp[hash/64] |= 1 << (hash % 64)
+}
+
+// Count returns the number of bits set.
+func (fp Fingerprint) Count() int {
+	count := 0
+	for _, word := range fp {
+		count += bits.OnesCount64(word)
+	}
+	return count
+}
+
+// Contains returns true if every bit set in sub is also set in fp, where both have the same length.
+func (fp Fingerprint) Contains(sub Fingerprint) bool {
+	if len(fp) != len(sub) {
+		return false
+	}
+	for i, word := range sub {
+		if word&^fp[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// hashFeature returns the hash of a feature, given as a kind and a sequence of colors.
+func hashFeature(kind byte, seq []int64) uint64 {
+	h := fnv.New64a()
+	var buf [binary.MaxVarintLen64]byte
+	h.Write([]byte{kind})
+	for _, val := range seq {
+		h.Write(buf[:binary.PutVarint(buf[:], val)])
+	}
+	return h.Sum64()
+}
+
+// lessSeq returns true if a sorts before b.
+func lessSeq(a, b []int64) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// PathFingerprint returns the fingerprint of G's path and cycle features, or ErrFingerprintBudget if G has more paths
+// than opts.MaxPaths.
+func PathFingerprint(G *Graph, opts FingerprintOpts) (Fingerprint, error) {
+	M, err := newMatchGraph(G)
+	if err != nil {
+		return nil, err
+	}
+	fp := newFingerprint(opts.NumBits)
+
+	numPaths := 0
+	withinBudget := func() bool {
+		numPaths++
+		return opts.MaxPaths <= 0 || numPaths <= opts.MaxPaths
+	}
+
+	var fwd, rev []int64
+	for start := range M.labels {
+		// Each path is found from both of its ends, which is harmless as both give the same feature
+		walked := walkPaths(M, start, opts.MaxPathLen, false, func(path []int) bool {
+			if !withinBudget() {
+				return false
+			}
+			fwd = pathSeq(M, path, fwd[:0])
+			rev = pathSeq(M, reversePath(path), rev[:0])
+			reversePath(path)
+			if lessSeq(rev, fwd) {
+				fwd, rev = rev, fwd
+			}
+			fp.set(hashFeature('p', fwd))
+			return true
+		})
+
+		// Each cycle is found from its lowest vertex, in both directions
+		walked = walked && walkPaths(M, start, opts.MaxCycleLen-1, true, func(path []int) bool {
+			if !withinBudget() {
+				return false
+			}
+			if len(path) < 3 {
+				return true
+			}
+			if _, closes := M.adj[path[len(path)-1]][start]; closes {
+				fp.set(hashFeature('c', cycleSeq(M, path)))
+			}
+			return true
+		})
+		if !walked {
+			return nil, ErrFingerprintBudget
+		}
+	}
+	return fp, nil
+}
+
+// GraphFingerprint returns the fingerprint of G's path, cycle, and neighborhood features, building G with the given
+// canonizer.
+func GraphFingerprint(canonizer IGraphCanonizer, G *Graph, opts FingerprintOpts) (Fingerprint, error) {
+	fp, err := PathFingerprint(G, opts)
+	if err != nil {
+		return nil, err
+	}
+	neighborhoods, err := NeighborhoodHashes(canonizer, G, opts.Radius)
+	if err != nil {
+		return nil, err
+	}
+	for _, hashes := range neighborhoods {
+		for _, hash := range hashes {
+			fp.set(hash)
+		}
+	}
+	return fp, nil
+}
+
+// walkPaths calls fn with each simple path from start of up to maxLen edges, where if higherOnly is set, the other
+// vertices must have a higher index than start.  The path passed to fn is only valid during the call.
+// The walk stops as soon as fn returns false, in which case walkPaths also returns false.
+func walkPaths(M *matchGraph, start, maxLen int, higherOnly bool, fn func(path []int) bool) bool {
+	if maxLen < 0 {
+		return true
+	}
+	path := make([]int, 0, maxLen+1)
+	onPath := make([]bool, len(M.labels))
+
+	var visit func(v int) bool
+	visit = func(v int) bool {
+		path = append(path, v)
+		onPath[v] = true
+		ok := fn(path)
+		if ok && len(path)-1 < maxLen {
+			for _, w := range M.neighbors[v] {
+				if !onPath[w] && (!higherOnly || w > start) {
+					if ok = visit(w); !ok {
+						break
+					}
+				}
+			}
+		}
+		onPath[v] = false
+		path = path[:len(path)-1]
+		return ok
+	}
+	return visit(start)
+}
+
+func reversePath(path []int) []int {
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// pathSeq appends the alternating vertex and edge colors along a path to seq.
+func pathSeq(M *matchGraph, path []int, seq []int64) []int64 {
+	for i, v := range path {
+		if i > 0 {
+			seq = append(seq, int64(M.adj[path[i-1]][v]))
+		}
+		seq = append(seq, int64(M.colors[v]))
+	}
+	return seq
+}
+
+// cycleSeq returns the smallest sequence of (vertex color, edge color) pairs around a cycle over every rotation and
+// direction, where the path's last vertex is adjacent to its first.
+func cycleSeq(M *matchGraph, path []int) []int64 {
+	L := len(path)
+	var best, seq []int64
+	for _, step := range []int{1, L - 1} {
+		for first := 0; first < L; first++ {
+			seq = seq[:0]
+			for i := 0; i < L; i++ {
+				v := path[(first+i*step)%L]
+				next := path[(first+(i+1)*step)%L]
+				seq = append(seq, int64(M.colors[v]), int64(M.adj[v][next]))
+			}
+			if best == nil || lessSeq(seq, best) {
+				best = append(best[:0], seq...)
+			}
+		}
+	}
+	return best
+}
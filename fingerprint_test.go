@@ -0,0 +1,63 @@
+package orca
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPathFingerprint(t *testing.T) {
+	fingerprint := func(text string) Fingerprint {
+		G, err := ParseGraphText(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fp, err := PathFingerprint(G, DefaultFingerprintOpts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fp
+	}
+
+	hexagon := fingerprint("1-2 2-3 3-4 4-5 5-6 6-1")
+	if relabeled := fingerprint("1-4 4-2 2-6 6-3 3-5 5-1"); !hexagon.Contains(relabeled) || !relabeled.Contains(hexagon) {
+		t.Fatal("expected relabeling to preserve the fingerprint")
+	}
+	path := fingerprint("1-2 2-3 3-4")
+	if !hexagon.Contains(path) || path.Contains(hexagon) {
+		t.Fatal("expected the hexagon's fingerprint to strictly contain the path's")
+	}
+	if hexagon.Contains(fingerprint("1-2 2-3 3-4 4-1")) {
+		t.Fatal("expected a 4-cycle to be screened out of a hexagon")
+	}
+	if hexagon.Contains(fingerprint("1-(2)-2")) || hexagon.Contains(fingerprint("v1:6")) {
+		t.Fatal("expected colors to be screened")
+	}
+	if hexagon.Count() == 0 || len(hexagon) != 1024/64 {
+		t.Fatal("unexpected fingerprint size")
+	}
+}
+
+func TestPathFingerprintBudget(t *testing.T) {
+	// K12 has millions of paths of up to 6 edges
+	var edges []string
+	for i := 1; i <= 12; i++ {
+		for j := i + 1; j <= 12; j++ {
+			edges = append(edges, fmt.Sprintf("%d-%d", i, j))
+		}
+	}
+	K12, err := ParseGraphText(strings.Join(edges, " "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := DefaultFingerprintOpts
+	opts.MaxPaths = 10000
+	if fp, err := PathFingerprint(K12, opts); err != ErrFingerprintBudget || fp != nil {
+		t.Fatalf("expected ErrFingerprintBudget, got %v", err)
+	}
+
+	opts.MaxPathLen, opts.MaxCycleLen = 2, 3
+	if _, err := PathFingerprint(K12, opts); err != nil {
+		t.Fatalf("expected short paths to fit the budget, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package orca
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// AttrMapping maps node and edge attributes of attribute-based formats (GraphML, node-link JSON) to VtxColor and
+// EdgeColor.  An attribute value is either an integer color or, if a registry is given, a registered color name
+// or alias.  Nodes and edges without the attribute (or when no attribute is named) get the default color (0).
+type AttrMapping struct {
+	VtxAttr    string             // node attribute holding the VtxColor
+	EdgeAttr   string             // edge attribute holding the EdgeColor
+	VtxColors  *VtxColorRegistry  // if set, VtxColors are read and written by name
+	EdgeColors *EdgeColorRegistry // if set, EdgeColors are read and written by name
+}
+
+// CanonicOrigAttr is the node attribute written with each vertex's VtxLabel in the source graph.
+const CanonicOrigAttr = "orca_orig"
+
+// CanonicStringAttr is the graph attribute written with the canonic string of the graph (see FormatCanonicString).
+const CanonicStringAttr = "orca_canonic"
+
+func (m *AttrMapping) vtxColor(val string) (VtxColor, error) {
+	if color, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return VtxColor(color), nil
+	}
+	if m.VtxColors != nil {
+		return m.VtxColors.Resolve(val)
+	}
+	return 0, errors.Errorf("invalid vertex color %q", val)
+}
+
+func (m *AttrMapping) edgeColor(val string) (EdgeColor, error) {
+	if color, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return EdgeColor(color), nil
+	}
+	if m.EdgeColors != nil {
+		return m.EdgeColors.Resolve(val)
+	}
+	return 0, errors.Errorf("invalid edge color %q", val)
+}
+
+// vtxValue returns the attribute value for the given color (a name if registered, otherwise an integer).
+func (m *AttrMapping) vtxValue(color VtxColor) string {
+	if m.VtxColors != nil {
+		if name := m.VtxColors.Name(color); name != "" {
+			return name
+		}
+	}
+	return strconv.FormatInt(int64(color), 10)
+}
+
+func (m *AttrMapping) edgeValue(color EdgeColor) string {
+	if m.EdgeColors != nil {
+		if name := m.EdgeColors.Name(color); name != "" {
+			return name
+		}
+	}
+	return strconv.FormatInt(int64(color), 10)
+}
+
+// idGraphBuilder builds a Graph from nodes and edges that reference nodes by arbitrary ids, assigning VtxLabels
+// 1..N in order of appearance.  Repeated edges are ignored and loops are rejected.
+type idGraphBuilder struct {
+	G     Graph
+	ids   map[string]VtxLabel
+	edges map[CanonicalEdge]bool
+}
+
+func newIDGraphBuilder() *idGraphBuilder {
+	return &idGraphBuilder{
+		ids:   make(map[string]VtxLabel),
+		edges: make(map[CanonicalEdge]bool),
+	}
+}
+
+func (B *idGraphBuilder) addVtx(id string, color VtxColor) error {
+	if _, exists := B.ids[id]; exists {
+		return errors.Errorf("duplicate node %q", id)
+	}
+	label := VtxLabel(len(B.G.Vtx) + 1)
+	B.ids[id] = label
+	B.G.Vtx = append(B.G.Vtx, Vtx{
+		Label: label,
+		Color: color,
+	})
+	return nil
+}
+
+func (B *idGraphBuilder) addEdge(source, target string, color EdgeColor) error {
+	va, foundA := B.ids[source]
+	vb, foundB := B.ids[target]
+	if !foundA || !foundB {
+		return errors.Errorf("edge %q-%q references a missing node", source, target)
+	}
+	if va == vb {
+		return errors.Errorf("loop at node %q is not supported", source)
+	}
+	e := Edge{
+		Va:    va,
+		Vb:    vb,
+		Color: color,
+	}
+	if key := e.FormCanonicalEdge(); !B.edges[key] {
+		B.edges[key] = true
+		B.G.Edges = append(B.G.Edges, e)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package orca
+
+import (
+	"github.com/pkg/errors"
+)
+
+/* Incremental edits:
+
+Rather than rebuilding the graph for each edit, ApplyEdits keeps every edge ever added (the supergraph) and tracks
+the edited graph as the subgraph with removed edges masked out, i.e. an EdgeSet like those the canonizer already
+catalogs (see FetchSubGraph).  Since subgraphs and their dags are keyed by EdgeSet, canonizing after an edit reuses
+the dags of any subgraph that was already visited, such as when an edit is undone, an edge is toggled, or ranking
+recurses into a subgraph shared with an earlier canonization.
+
+An edge that is new to the supergraph is given the next edge index, so it's added as removed to every cataloged
+subgraph (which never contained it).  The catalog is only discarded when a removed vertex is added back with a
+different color, as its color is part of every dag reaching it.  BuildGraph releases the supergraph and the catalog.
+
+The result matches canonizing the edited graph from scratch, except for graphs affected by the canonizer's known
+issue with ambiguous leaf order (see README), whose form already depends on how the graph was built.
+*/
+
+// EditOp is a kind of GraphEdit.
+type EditOp int
+
+const (
+	EditAddVtx     EditOp = iota + 1 // adds GraphEdit.Vtx
+	EditRemoveVtx                    // removes the vertex labeled GraphEdit.Vtx.Label along with its edges
+	EditAddEdge                      // adds GraphEdit.Edge
+	EditRemoveEdge                   // removes GraphEdit.Edge (matching its color)
+)
+
+// GraphEdit is a single change to a graph (see ApplyEdits).
+type GraphEdit struct {
+	Op   EditOp
+	Vtx  Vtx
+	Edge Edge
+}
+
+// ApplyEdits applies edits to G in order, leaving G unchanged if any edit fails.
+func (G *Graph) ApplyEdits(edits ...GraphEdit) error {
+	vtx := append([]Vtx(nil), G.Vtx...)
+	edges := append([]Edge(nil), G.Edges...)
+
+	findVtx := func(vi VtxLabel) int {
+		for i, v := range vtx {
+			if v.Label == vi {
+				return i
+			}
+		}
+		return -1
+	}
+	findEdge := func(e Edge) int {
+		ce := e.FormCanonicalEdge()
+		for i, ei := range edges {
+			if ei.FormCanonicalEdge() == ce {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for _, edit := range edits {
+		switch edit.Op {
+		case EditAddVtx:
+			if edit.Vtx.Label < 1 || findVtx(edit.Vtx.Label) >= 0 {
+				return errors.Errorf("failed to add vertex: VtxLabel %d is invalid or already added", edit.Vtx.Label)
+			}
+			vtx = append(vtx, edit.Vtx)
+		case EditRemoveVtx:
+			i := findVtx(edit.Vtx.Label)
+			if i < 0 {
+				return errors.Errorf("failed to remove vertex: VtxLabel %d not found", edit.Vtx.Label)
+			}
+			vtx = append(vtx[:i], vtx[i+1:]...)
+			remaining := edges[:0]
+			for _, e := range edges {
+				if e.Va != edit.Vtx.Label && e.Vb != edit.Vtx.Label {
+					remaining = append(remaining, e)
+				}
+			}
+			edges = remaining
+		case EditAddEdge:
+			if findVtx(edit.Edge.Va) < 0 || findVtx(edit.Edge.Vb) < 0 {
+				return errors.Errorf("failed to add edge %d-%d: no such vertex", edit.Edge.Va, edit.Edge.Vb)
+			}
+			if findEdge(edit.Edge) >= 0 {
+				return errors.Errorf("failed to add edge %d-%d: already exists", edit.Edge.Va, edit.Edge.Vb)
+			}
+			edges = append(edges, edit.Edge)
+		case EditRemoveEdge:
+			i := findEdge(edit.Edge)
+			if i < 0 {
+				return errors.Wrapf(ErrEdgeNotFound, "edge %d-%d", edit.Edge.Va, edit.Edge.Vb)
+			}
+			edges = append(edges[:i], edges[i+1:]...)
+		default:
+			return errors.Errorf("unknown edit op %d", edit.Op)
+		}
+	}
+
+	G.Vtx = vtx
+	G.Edges = edges
+	return nil
+}
+
+// RecanonizeGraph applies edits to G, which must be the graph the canonizer most recently built (see CanonizeGraph),
+// and returns the canonical form of the edited graph.  If an edit fails, G and the canonizer are left unchanged.
+func RecanonizeGraph(canonizer IGraphCanonizer, G *Graph, edits ...GraphEdit) (*Canonic, error) {
+	edited := &Graph{
+		Vtx:   G.Vtx,
+		Edges: G.Edges,
+	}
+	if err := edited.ApplyEdits(edits...); err != nil {
+		return nil, err
+	}
+	if err := ApplyEdits(canonizer, edits...); err != nil {
+		return nil, err
+	}
+	*G = *edited
+
+	labeling, err := CanonicLabeling(canonizer)
+	if err != nil {
+		return nil, err
+	}
+	return relabel(G, labeling), nil
+}
+
+// ApplyEdits edits the graph the canonizer most recently built in order, after which Canonize and CanonicLabeling
+// reflect the edited graph.  Unlike rebuilding the edited graph, work from earlier canonizations is kept and reused
+// where still valid (see above).  If an edit fails, the edits before it remain applied.
+func ApplyEdits(canonizer IGraphCanonizer, edits ...GraphEdit) error {
+	ctx, err := encoderFor(canonizer)
+	if err != nil {
+		return err
+	}
+	return ctx.ApplyEdits(edits...)
+}
+
+// ApplyEdits -- see ApplyEdits()
+func (ctx *encoderCtx) ApplyEdits(edits ...GraphEdit) error {
+	if ctx.Error() != nil {
+		return ctx.Error()
+	}
+	if ctx.editMask == nil {
+		ctx.editMask = make(EdgeSet, len(ctx.edgeSetTmp))
+		ctx.removedVtx = make(map[VtxLabel]bool)
+	}
+
+	for _, edit := range edits {
+		var err error
+		switch edit.Op {
+		case EditAddVtx:
+			err = ctx.editAddVtx(edit.Vtx)
+		case EditRemoveVtx:
+			err = ctx.editRemoveVtx(edit.Vtx.Label)
+		case EditAddEdge:
+			err = ctx.editAddEdge(edit.Edge)
+		case EditRemoveEdge:
+			err = ctx.editRemoveEdge(edit.Edge)
+		default:
+			err = errors.Errorf("unknown edit op %d", edit.Op)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isVtxPresent returns true if vi was added and not since removed.
+func (ctx *encoderCtx) isVtxPresent(vi VtxLabel) bool {
+	_, exists := ctx.vtxIndex[vi]
+	return exists && !ctx.removedVtx[vi]
+}
+
+func (ctx *encoderCtx) editAddVtx(v Vtx) error {
+	if v.Label < 1 {
+		return errors.New("failed to add vertex: VtxLabel must be > 0")
+	}
+	idx, exists := ctx.vtxIndex[v.Label]
+	if !exists {
+		ctx.vtxIndex[v.Label] = uint32(len(ctx.vtx))
+		ctx.vtx = append(ctx.vtx, dagVtx{
+			VtxLabel: v.Label,
+			VtxColor: v.Color,
+		})
+		return nil
+	}
+	if !ctx.removedVtx[v.Label] {
+		return errors.Errorf("failed to add vertex: VtxLabel %d already added", v.Label)
+	}
+
+	delete(ctx.removedVtx, v.Label)
+	if ctx.vtx[idx].VtxColor != v.Color {
+		ctx.vtx[idx].VtxColor = v.Color
+		ctx.EndGraph() // refreshes the vertex's color on its neighbors' edges
+		ctx.subGraphs.Clear()
+	}
+	return nil
+}
+
+func (ctx *encoderCtx) editRemoveVtx(vi VtxLabel) error {
+	if !ctx.isVtxPresent(vi) {
+		return errors.Errorf("failed to remove vertex: VtxLabel %d not found", vi)
+	}
+	for _, edge := range ctx.vtxForLabel(vi).edges {
+		ctx.setEdgeRemoved(edge.FormCanonicalEdge(vi), true)
+	}
+	ctx.removedVtx[vi] = true
+	return nil
+}
+
+func (ctx *encoderCtx) editAddEdge(e Edge) error {
+	if !ctx.isVtxPresent(e.Va) || !ctx.isVtxPresent(e.Vb) {
+		return errors.Errorf("failed to add edge %d-%d: no such vertex", e.Va, e.Vb)
+	}
+	ce := e.FormCanonicalEdge()
+	if _, found := ctx.edgeMap[ce]; found {
+		if !ctx.setEdgeRemoved(ce, false) {
+			return errors.Errorf("failed to add edge %d-%d: already exists", e.Va, e.Vb)
+		}
+		return nil
+	}
+
+	ctx.AddEdge(e)
+	ctx.EndGraph()
+	if err := ctx.Error(); err != nil {
+		return err
+	}
+
+	// The new edge is absent from every cataloged subgraph, so re-key each with the new edge's bit set
+	newIdx := len(ctx.edges) - 1
+	subs := ctx.subGraphs.Values()
+	ctx.subGraphs.Clear()
+	for _, sub := range subs {
+		subG := sub.(*subGraph)
+		subG.edgeSet = withEdgeRemoved(subG.edgeSet, len(ctx.edgeSetTmp), newIdx)
+		ctx.subGraphs.Put(subG.edgeSet, subG)
+	}
+	ctx.editMask = withEdgeRemoved(ctx.editMask, len(ctx.edgeSetTmp), newIdx)
+	ctx.setEdgeRemoved(ce, false)
+	return nil
+}
+
+func (ctx *encoderCtx) editRemoveEdge(e Edge) error {
+	ce := e.FormCanonicalEdge()
+	if _, found := ctx.edgeMap[ce]; !found || !ctx.setEdgeRemoved(ce, true) {
+		return errors.Wrapf(ErrEdgeNotFound, "edge %d-%d", e.Va, e.Vb)
+	}
+	return nil
+}
+
+// setEdgeRemoved sets whether the given edge is masked out of the edited graph, returning false if it already was.
+func (ctx *encoderCtx) setEdgeRemoved(e CanonicalEdge, removed bool) bool {
+	idx := ctx.edgeMap[e]
+	maskIdx := idx >> 6
+	edgeBit := uint64(1) << (idx & 0x3F)
+	if ((ctx.editMask[maskIdx] & edgeBit) != 0) == removed {
+		return false
+	}
+	ctx.editMask[maskIdx] ^= edgeBit
+	return true
+}
+
+// withEdgeRemoved returns edgeSet extended to the given number of words with the bit for edge idx set.
+func withEdgeRemoved(edgeSet EdgeSet, numWords int, idx int) EdgeSet {
+	for len(edgeSet) < numWords {
+		edgeSet = append(edgeSet, 0)
+	}
+	edgeSet[idx>>6] |= uint64(1) << (idx & 0x3F)
+	return edgeSet
+}
+
+// presentVtx returns a copy of ctx.vtx without removed vertices, where each vertex only has edges present in subG.
+func (ctx *encoderCtx) presentVtx(subG *subGraph) []dagVtx {
+	vtx := make([]dagVtx, 0, len(ctx.vtx))
+	for _, v := range ctx.vtx {
+		if ctx.removedVtx[v.VtxLabel] {
+			continue
+		}
+		edges := make([]dagEdge, 0, len(v.edges))
+		for _, edge := range v.edges {
+			if ctx.IsEdgePresent(subG.edgeSet, edge.FormCanonicalEdge(v.VtxLabel)) {
+				edges = append(edges, edge)
+			}
+		}
+		v.edges = edges
+		vtx = append(vtx, v)
+	}
+	return vtx
+}
@@ -0,0 +1,73 @@
+package orca
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecanonizeGraph(t *testing.T) {
+	// A colored, asymmetric starting graph (a chain with a branch and a ring)
+	G, err := ParseGraphText("v1:6 v2:6 v3:8 v4:6 v5:7 v6:6 1-2 2-(2)-3 2-4 4-5 5-6 6-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonizer := NewCanonizer(DefaultCanonizerOpts)
+	original, err := CanonizeGraph(canonizer, G)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(desc string, edits ...GraphEdit) *Canonic {
+		C, err := RecanonizeGraph(canonizer, G, edits...)
+		if err != nil {
+			t.Fatalf("%s: %v", desc, err)
+		}
+		fresh, err := CanonizeGraph(NewCanonizer(DefaultCanonizerOpts), G)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(C.Encoding(), fresh.Encoding()) {
+			t.Fatalf("%s: incremental %v differs from rebuilt %v", desc, C.Graph.String(), fresh.Graph.String())
+		}
+		if C.NumVerts() != len(G.Vtx) || C.NumEdges() != len(G.Edges) {
+			t.Fatalf("%s: expected %d vertices and %d edges", desc, len(G.Vtx), len(G.Edges))
+		}
+		return C
+	}
+
+	check("remove ring edge", GraphEdit{Op: EditRemoveEdge, Edge: Edge{Va: 6, Vb: 4}})
+	check("add new edge", GraphEdit{Op: EditAddEdge, Edge: Edge{Va: 1, Vb: 6, Color: 3}})
+	check("add vertex and edge",
+		GraphEdit{Op: EditAddVtx, Vtx: Vtx{Label: 7, Color: 9}},
+		GraphEdit{Op: EditAddEdge, Edge: Edge{Va: 7, Vb: 3}},
+	)
+	check("remove vertex", GraphEdit{Op: EditRemoveVtx, Vtx: Vtx{Label: 2}})
+	check("re-add vertex with a new color", GraphEdit{Op: EditAddVtx, Vtx: Vtx{Label: 2, Color: 5}})
+
+	// Undoing every edit restores the original form
+	C := check("undo",
+		GraphEdit{Op: EditRemoveVtx, Vtx: Vtx{Label: 7}},
+		GraphEdit{Op: EditRemoveEdge, Edge: Edge{Va: 1, Vb: 6, Color: 3}},
+		GraphEdit{Op: EditAddVtx, Vtx: Vtx{Label: 7}},
+		GraphEdit{Op: EditRemoveVtx, Vtx: Vtx{Label: 7}},
+		GraphEdit{Op: EditRemoveVtx, Vtx: Vtx{Label: 2}},
+		GraphEdit{Op: EditAddVtx, Vtx: Vtx{Label: 2, Color: 6}},
+		GraphEdit{Op: EditAddEdge, Edge: Edge{Va: 1, Vb: 2}},
+		GraphEdit{Op: EditAddEdge, Edge: Edge{Va: 2, Vb: 3, Color: 2}},
+		GraphEdit{Op: EditAddEdge, Edge: Edge{Va: 2, Vb: 4}},
+		GraphEdit{Op: EditAddEdge, Edge: Edge{Va: 4, Vb: 6}},
+	)
+	if !bytes.Equal(C.Encoding(), original.Encoding()) {
+		t.Fatalf("expected the original form after undoing edits, got %v", C.Graph.String())
+	}
+
+	// A failed edit leaves both the graph and canonizer unchanged
+	before := G.String()
+	if _, err = RecanonizeGraph(canonizer, G,
+		GraphEdit{Op: EditRemoveEdge, Edge: Edge{Va: 1, Vb: 2}},
+		GraphEdit{Op: EditRemoveEdge, Edge: Edge{Va: 1, Vb: 5}},
+	); err == nil || G.String() != before {
+		t.Fatal("expected a failed edit to change nothing")
+	}
+	check("after failure")
+}
@@ -0,0 +1,177 @@
+package orca
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+/* Graph text notation:
+
+A graph is written as a list of tokens separated by spaces and/or commas:
+
+    1-2 1-3 2-3      edges with the default EdgeColor (0), as in the README
+    3-(7)-4          an edge with EdgeColor 7
+    1-2-(7)-3-4      a path, shorthand for 1-2 2-(7)-3 3-4
+    v5:3             vertex 5 has VtxColor 3
+    v6               vertex 6 exists (needed only for an isolated vertex with the default VtxColor)
+
+A graph has vertices 1..N, where N is the largest label appearing, and vertices not given a color have the default
+VtxColor (0).  So "1-2 2-3 v2:1" is a path of three vertices whose middle vertex has color 1, and "v3" is three
+isolated vertices.  The older GraphIn.String form ("v1: 3  1-(20)-2, ") is also accepted.
+*/
+
+var ErrBadGraphText = errors.New("bad graph text")
+
+// ParseGraphText parses a graph written in the graph text notation.
+func ParseGraphText(text string) (*Graph, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t' || r == '\n' || r == '\r'
+	})
+
+	G := &Graph{}
+	colors := make(map[VtxLabel]VtxColor)
+	maxLabel := VtxLabel(0)
+	noteLabel := func(label VtxLabel) {
+		if label > maxLabel {
+			maxLabel = label
+		}
+	}
+
+	for i := 0; i < len(fields); i++ {
+		token := fields[i]
+
+		if token[0] == 'v' {
+			// "v1: 3" splits across two fields
+			if strings.HasSuffix(token, ":") && i+1 < len(fields) {
+				i++
+				token += fields[i]
+			}
+			labelStr, colorStr := token[1:], ""
+			colon := strings.IndexByte(labelStr, ':')
+			if colon >= 0 {
+				labelStr, colorStr = labelStr[:colon], labelStr[colon+1:]
+			}
+			label, err := parseTextLabel(labelStr)
+			if err != nil {
+				return nil, errors.Wrapf(ErrBadGraphText, "%q: %v", token, err)
+			}
+			if colon >= 0 {
+				color, err := strconv.ParseInt(colorStr, 10, 64)
+				if err != nil || color < 0 {
+					return nil, errors.Wrapf(ErrBadGraphText, "%q: invalid vertex color", token)
+				}
+				colors[label] = VtxColor(color)
+			}
+			noteLabel(label)
+			continue
+		}
+
+		// A path of one or more edges
+		parts := strings.Split(token, "-")
+		va, err := parseTextLabel(parts[0])
+		if err != nil || len(parts) < 2 {
+			return nil, errors.Wrapf(ErrBadGraphText, "%q: expected an edge", token)
+		}
+		noteLabel(va)
+		for j := 1; j < len(parts); j++ {
+			e := Edge{Va: va}
+
+			// An edge color is a part of the form "(7)", or "(" followed by a negative color, e.g. "1-(-7)-2"
+			if strings.HasPrefix(parts[j], "(") {
+				colorStr := parts[j]
+				if colorStr == "(" && j+1 < len(parts) {
+					j++
+					colorStr = "(-" + parts[j]
+				}
+				if !strings.HasSuffix(colorStr, ")") || j+1 >= len(parts) {
+					return nil, errors.Wrapf(ErrBadGraphText, "%q: invalid edge color", token)
+				}
+				color, err := strconv.ParseInt(colorStr[1:len(colorStr)-1], 10, 64)
+				if err != nil {
+					return nil, errors.Wrapf(ErrBadGraphText, "%q: invalid edge color", token)
+				}
+				e.Color = EdgeColor(color)
+				j++
+			}
+
+			if e.Vb, err = parseTextLabel(parts[j]); err != nil {
+				return nil, errors.Wrapf(ErrBadGraphText, "%q: %v", token, err)
+			}
+			if e.Va == e.Vb {
+				return nil, errors.Wrapf(ErrBadGraphText, "%q: loops are not supported", token)
+			}
+			noteLabel(e.Vb)
+			G.Edges = append(G.Edges, e)
+			va = e.Vb
+		}
+	}
+
+	G.Vtx = make([]Vtx, maxLabel)
+	for i := range G.Vtx {
+		label := VtxLabel(i + 1)
+		G.Vtx[i] = Vtx{
+			Label: label,
+			Color: colors[label],
+		}
+	}
+	return G, nil
+}
+
+func parseTextLabel(str string) (VtxLabel, error) {
+	label, err := strconv.ParseUint(str, 10, 32)
+	if err != nil || label == 0 {
+		return 0, errors.Errorf("invalid vertex %q", str)
+	}
+	if label > uint64(MaxParsedVtx) {
+		return 0, errors.Errorf("vertex %q exceeds MaxParsedVtx", str)
+	}
+	return VtxLabel(label), nil
+}
+
+// ExportGraphText parses a graph in the graph text notation and sends it to Gout.  If an error is returned, nothing
+// is sent.
+func ExportGraphText(text string, Gout GraphOut) error {
+	G, err := ParseGraphText(text)
+	if err != nil {
+		return err
+	}
+	G.Export(Gout)
+	return nil
+}
+
+// String returns G in the graph text notation: colored vertices (in VtxLabel order), then edges (in the order
+// given), followed by any isolated vertices with the default color.
+func (G *Graph) String() string {
+	vtx := append([]Vtx(nil), G.Vtx...)
+	sort.Slice(vtx, func(i, j int) bool {
+		return vtx[i].Label < vtx[j].Label
+	})
+
+	tokens := make([]string, 0, len(vtx)+len(G.Edges))
+	for _, v := range vtx {
+		if v.Color != 0 {
+			tokens = append(tokens, "v"+strconv.FormatUint(uint64(v.Label), 10)+":"+strconv.FormatInt(int64(v.Color), 10))
+		}
+	}
+
+	hasEdge := make(map[VtxLabel]bool, len(vtx))
+	for _, e := range G.Edges {
+		hasEdge[e.Va] = true
+		hasEdge[e.Vb] = true
+		token := strconv.FormatUint(uint64(e.Va), 10) + "-"
+		if e.Color != 0 {
+			token += "(" + strconv.FormatInt(int64(e.Color), 10) + ")-"
+		}
+		tokens = append(tokens, token+strconv.FormatUint(uint64(e.Vb), 10))
+	}
+
+	for _, v := range vtx {
+		if v.Color == 0 && !hasEdge[v.Label] {
+			tokens = append(tokens, "v"+strconv.FormatUint(uint64(v.Label), 10))
+		}
+	}
+	return strings.Join(tokens, " ")
+}
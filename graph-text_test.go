@@ -0,0 +1,49 @@
+package orca
+
+import (
+	"testing"
+)
+
+func TestGraphText(t *testing.T) {
+	round := []string{
+		"",
+		"1-2 1-3 2-3 1-4 3-5 4-5 2-6 4-6 5-6",
+		"v2:1 v3:12 1-2 2-(7)-3 3-(-2)-4 v5 v6",
+	}
+	for _, text := range round {
+		G, err := ParseGraphText(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if G.String() != text {
+			t.Fatalf("expected %q, got %q", text, G.String())
+		}
+
+		// GraphIn.String emits the same notation
+		Gin, Gout := NewGraphIO()
+		go G.Export(Gout)
+		if str := Gin.String(); str != text {
+			t.Fatalf("expected %q, got %q", text, str)
+		}
+	}
+
+	// Paths, commas, and the older GraphIn.String form
+	for text, expected := range map[string]string{
+		"1-2-(7)-3-4, v6":                   "1-2 2-(7)-3 3-4 v5 v6",
+		"v1: 3  v2: 0  1-(20)-2, 2-(0)-3, ": "v1:3 1-(20)-2 2-3",
+	} {
+		G, err := ParseGraphText(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if G.String() != expected {
+			t.Fatalf("expected %q, got %q", expected, G.String())
+		}
+	}
+
+	for _, text := range []string{"1-1", "1-", "0-1", "v1:x", "1-(2-3", "a-b", "v4294967295", "1-4294967295"} {
+		if _, err := ParseGraphText(text); err == nil {
+			t.Fatalf("expected error for %q", text)
+		}
+	}
+}
@@ -67,7 +67,8 @@ type graph struct {
 	IGraphBuilder,
 
 	fatalErr     error
-	colorDefs    map[VtxColor]VtxColorDef
+	colorDefs    *VtxColorRegistry
+	edgeDefs     *EdgeColorRegistry
 	vtxIndex   map[VtxLabel]uint32       // maps a VtxLabel to an index into vtx[]
 	vtx          []dagVtx                  // all the graph's vertices with dagEdgeOut edges
 	edgesOut     []dagEdge                 // backing buf for vtx[].edges
@@ -77,10 +78,19 @@ type graph struct {
 	subGraphs    redblacktree.Tree         // maps EdgeSet => SubGraph in log N time
 	subGraphPool SubGraphPool              // SubGraph (re)allocation pool
 	edgeSetTmp   EdgeSet
+	editMask     EdgeSet                   // if non-nil, the edges removed by ApplyEdits (see graph-edit.go)
+	removedVtx   map[VtxLabel]bool         // vertices removed by ApplyEdits
 }
 
-func (G *graph) init(subGraphPool SubGraphPool) {
-	G.colorDefs = make(map[VtxColor]VtxColorDef, 16)
+func (G *graph) init(subGraphPool SubGraphPool, colorDefs *VtxColorRegistry, edgeDefs *EdgeColorRegistry) {
+	if colorDefs == nil {
+		colorDefs = NewVtxColorRegistry()
+	}
+	if edgeDefs == nil {
+		edgeDefs = NewEdgeColorRegistry()
+	}
+	G.colorDefs = colorDefs
+	G.edgeDefs = edgeDefs
 	G.subGraphs = redblacktree.Tree{
 		Comparator: func(a, b interface{}) int {
 			a0 := a.(EdgeSet)
@@ -95,8 +105,12 @@ func (G *graph) vtxForLabel(vi VtxLabel) dagVtx {
 	return G.vtx[G.vtxIndex[vi]]
 }
 
+// SelfSubGraph returns the SubGraph of every edge (as edited, see ApplyEdits).
 func (G *graph) SelfSubGraph() SubGraph {
-	allEdges := make(EdgeSet, len(G.edgeSetTmp))
+	allEdges := G.editMask
+	if allEdges == nil {
+		allEdges = make(EdgeSet, len(G.edgeSetTmp))
+	}
 
 	// for i := range G.edgeSetTmp {
 	// 	allEdges[i] = 0xFFFFFFFFFFFFFFFF
@@ -106,6 +120,8 @@ func (G *graph) SelfSubGraph() SubGraph {
 	return subG
 }
 
+// FetchSubGraph returns the SubGraph having the edges of from, less removeEdges, where a SubGraph's EdgeSet is
+// shared with the subgraph catalog and must not be modified.
 func (G *graph) FetchSubGraph(from EdgeSet, removeEdges []Edge) (subG SubGraph, err error) {
 	copy(G.edgeSetTmp, from)
 
@@ -187,17 +203,6 @@ func (G *graph) NumEdges() int {
 // 	G.EndGraph()
 // }
 
-// func (G *graph) AddVtxColors(defs []VtxColorDef) error {
-
-// 	for _, newDef := range defs {
-// 		def, defExists := G.colorDefs[newDef.VtxColor]
-// 		if defExists {
-// 			return nil, errors.
-// 		}
-// 	}
-// 	colorDefs
-// }
-
 
 func (G *graph) BuildGraph(Gin GraphIn) error {
     G.BeginGraph(32, 32)
@@ -251,6 +256,9 @@ func (G *graph) BeginGraph(numVtxHint, numEdgesHint int) {
 	// TODO: move SubGraphs back into G.subGraphPool
 	G.subGraphs.Clear()
 
+	G.editMask = nil
+	G.removedVtx = nil
+
 	if G.edgeMapCap < numEdgesHint {
 		G.edgeMapCap = (numEdgesHint + 0xF) &^ 0xF
 		G.edgeMap = make(map[CanonicalEdge]edgeIdx, G.edgeMapCap)
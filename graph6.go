@@ -0,0 +1,517 @@
+package orca
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+/* graph6, sparse6, and digraph6:
+
+These are nauty's compact, printable, one-graph-per-line formats (see users.cecs.anu.edu.au/~bdm/data/formats.txt).
+Vertex i of a graph6 graph (zero-based) becomes VtxLabel i+1 and all colors are left at their default (0).
+
+Since go-orca graphs are undirected, a digraph6 arc u->v is represented by subdividing it: an extra vertex colored
+ArcVtxColor is joined to u by an edge colored ArcTailColor and to v by an edge colored ArcHeadColor.  The arc
+vertices follow the digraph's own vertices, so a digraph on n vertices with m arcs becomes a graph on n+m vertices.
+
+Loops and multiple edges (permitted by sparse6 and digraph6) are not supported by go-orca and are rejected.
+*/
+
+// Graph6Format names one of nauty's formats.
+type Graph6Format int
+
+const (
+	Graph6 Graph6Format = iota
+	Sparse6
+	Digraph6
+)
+
+// Colors used to represent digraph6 arcs (see above)
+const (
+	ArcVtxColor  = VtxColor(1)
+	ArcTailColor = EdgeColor(1)
+	ArcHeadColor = EdgeColor(2)
+)
+
+var ErrBadGraph6 = errors.New("bad graph6 data")
+
+// ParseGraph6 parses a single graph in graph6, sparse6, or digraph6 format (detected from its first char),
+// optionally preceded by a ">>graph6<<" style header.
+func ParseGraph6(line []byte) (*Graph, error) {
+	line = bytes.TrimRight(line, "\r\n")
+	for _, header := range []string{">>graph6<<", ">>sparse6<<", ">>digraph6<<"} {
+		line = bytes.TrimPrefix(line, []byte(header))
+	}
+	if len(line) == 0 {
+		return nil, errors.Wrap(ErrBadGraph6, "empty line")
+	}
+
+	switch line[0] {
+	case ':':
+		return parseSparse6(line[1:])
+	case '&':
+		return parseDigraph6(line[1:])
+	default:
+		return parseGraph6(line)
+	}
+}
+
+// graph6Bits reads the 6-bit groups of a graph6 style bit vector, most significant bit first.
+type graph6Bits struct {
+	data []byte
+	pos  int // bit position
+}
+
+func (B *graph6Bits) remaining() int {
+	return 6*len(B.data) - B.pos
+}
+
+func (B *graph6Bits) read(numBits int) uint64 {
+	val := uint64(0)
+	for i := 0; i < numBits; i++ {
+		ch := B.data[B.pos/6] - 63
+		bit := (ch >> (5 - B.pos%6)) & 1
+		val = (val << 1) | uint64(bit)
+		B.pos++
+	}
+	return val
+}
+
+func checkGraph6Chars(data []byte) error {
+	for i, ch := range data {
+		if ch < 63 || ch > 126 {
+			return errors.Wrapf(ErrBadGraph6, "invalid char %q at offset %d", ch, i)
+		}
+	}
+	return nil
+}
+
+// parseGraph6Size parses N(n), returning n and the remaining data.
+func parseGraph6Size(data []byte) (int, []byte, error) {
+	if err := checkGraph6Chars(data); err != nil {
+		return 0, nil, err
+	}
+	numBytes := 1
+	switch {
+	case len(data) >= 2 && data[0] == 126 && data[1] == 126:
+		data, numBytes = data[2:], 6
+	case len(data) >= 1 && data[0] == 126:
+		data, numBytes = data[1:], 3
+	}
+	if len(data) < numBytes {
+		return 0, nil, errors.Wrap(ErrBadGraph6, "truncated vertex count")
+	}
+	bits := graph6Bits{data: data[:numBytes]}
+	n := bits.read(6 * numBytes)
+	if n > uint64(MaxParsedVtx) {
+		return 0, nil, errors.Wrapf(ErrBadGraph6, "vertex count %d exceeds MaxParsedVtx", n)
+	}
+	return int(n), data[numBytes:], nil
+}
+
+func newGraph6Graph(n int) *Graph {
+	G := &Graph{
+		Vtx: make([]Vtx, n),
+	}
+	for i := range G.Vtx {
+		G.Vtx[i].Label = VtxLabel(i + 1)
+	}
+	return G
+}
+
+func parseGraph6(data []byte) (*Graph, error) {
+	n, data, err := parseGraph6Size(data)
+	if err != nil {
+		return nil, err
+	}
+	numBits := n * (n - 1) / 2
+	if len(data) != (numBits+5)/6 {
+		return nil, errors.Wrapf(ErrBadGraph6, "expected %d bytes of edge data, got %d", (numBits+5)/6, len(data))
+	}
+
+	G := newGraph6Graph(n)
+	bits := graph6Bits{data: data}
+	for j := 1; j < n; j++ {
+		for i := 0; i < j; i++ {
+			if bits.read(1) != 0 {
+				G.Edges = append(G.Edges, Edge{Va: VtxLabel(i + 1), Vb: VtxLabel(j + 1)})
+			}
+		}
+	}
+	return G, nil
+}
+
+func parseSparse6(data []byte) (*Graph, error) {
+	n, data, err := parseGraph6Size(data)
+	if err != nil {
+		return nil, err
+	}
+	k := sparse6BitsPerVtx(n)
+
+	G := newGraph6Graph(n)
+	seen := make(map[[2]int]bool)
+	bits := graph6Bits{data: data}
+	v := 0
+	for bits.remaining() >= 1+k {
+		b := bits.read(1)
+		x := int(bits.read(k))
+		if b != 0 {
+			v++
+		}
+		if x >= n || v >= n {
+			break // padding
+		}
+		if x > v {
+			v = x
+			continue
+		}
+		if x == v {
+			return nil, errors.Wrapf(ErrBadGraph6, "loop at vertex %d is not supported", v)
+		}
+		if seen[[2]int{x, v}] {
+			return nil, errors.Wrapf(ErrBadGraph6, "multiple edges between %d and %d are not supported", x, v)
+		}
+		seen[[2]int{x, v}] = true
+		G.Edges = append(G.Edges, Edge{Va: VtxLabel(x + 1), Vb: VtxLabel(v + 1)})
+	}
+	return G, nil
+}
+
+func parseDigraph6(data []byte) (*Graph, error) {
+	n, data, err := parseGraph6Size(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != (n*n+5)/6 {
+		return nil, errors.Wrapf(ErrBadGraph6, "expected %d bytes of arc data, got %d", (n*n+5)/6, len(data))
+	}
+
+	G := newGraph6Graph(n)
+	bits := graph6Bits{data: data}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if bits.read(1) == 0 {
+				continue
+			}
+			if i == j {
+				return nil, errors.Wrapf(ErrBadGraph6, "loop at vertex %d is not supported", i)
+			}
+			arcVtx := VtxLabel(len(G.Vtx) + 1)
+			G.Vtx = append(G.Vtx, Vtx{Label: arcVtx, Color: ArcVtxColor})
+			G.Edges = append(G.Edges,
+				Edge{Va: VtxLabel(i + 1), Vb: arcVtx, Color: ArcTailColor},
+				Edge{Va: arcVtx, Vb: VtxLabel(j + 1), Color: ArcHeadColor},
+			)
+		}
+	}
+	return G, nil
+}
+
+// sparse6BitsPerVtx returns the number of bits needed to represent n-1.
+func sparse6BitsPerVtx(n int) int {
+	k := 0
+	for i := n - 1; i > 0; i >>= 1 {
+		k++
+	}
+	return k
+}
+
+// graph6Writer packs bits into 6-bit printable chars, most significant bit first.
+type graph6Writer struct {
+	out   []byte
+	cur   byte
+	nbits int
+}
+
+func (W *graph6Writer) write(val uint64, numBits int) {
+	for i := numBits - 1; i >= 0; i-- {
+		W.cur = (W.cur << 1) | byte((val>>i)&1)
+		W.nbits++
+		if W.nbits == 6 {
+			W.out = append(W.out, W.cur+63)
+			W.cur, W.nbits = 0, 0
+		}
+	}
+}
+
+// pad completes the final char with the given bit value.
+func (W *graph6Writer) pad(bit uint64) {
+	for W.nbits != 0 {
+		W.write(bit, 1)
+	}
+}
+
+func (W *graph6Writer) writeSize(n int) {
+	switch {
+	case n <= 62:
+		W.out = append(W.out, byte(n+63))
+	case n <= 258047:
+		W.out = append(W.out, 126)
+		W.write(uint64(n), 18)
+	default:
+		W.out = append(W.out, 126, 126)
+		W.write(uint64(n), 36)
+	}
+}
+
+// graph6Indices maps each VtxLabel of G to a zero-based index (in VtxLabel order), also returning any edge
+// (as index pairs) and any edge or vertex colors which graph6 can't represent.
+func graph6Indices(G *Graph) (map[VtxLabel]int, [][2]int, error) {
+	labels := make([]VtxLabel, len(G.Vtx))
+	for i, v := range G.Vtx {
+		if v.Color != 0 {
+			return nil, nil, errors.Wrapf(ErrBadGraph6, "vertex %d has a color (graph6 is uncolored)", v.Label)
+		}
+		labels[i] = v.Label
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i] < labels[j]
+	})
+	idxOf := make(map[VtxLabel]int, len(labels))
+	for i, vi := range labels {
+		idxOf[vi] = i
+	}
+
+	edges := make([][2]int, 0, len(G.Edges))
+	for _, e := range G.Edges {
+		a, foundA := idxOf[e.Va]
+		b, foundB := idxOf[e.Vb]
+		switch {
+		case !foundA || !foundB:
+			return nil, nil, errors.Errorf("edge %d-%d references a missing vertex", e.Va, e.Vb)
+		case e.Color != 0:
+			return nil, nil, errors.Wrapf(ErrBadGraph6, "edge %d-%d has a color (graph6 is uncolored)", e.Va, e.Vb)
+		case a == b:
+			return nil, nil, errors.Wrapf(ErrBadGraph6, "loop at vertex %d", e.Va)
+		}
+		edges = append(edges, [2]int{min(a, b), max(a, b)})
+	}
+	return idxOf, edges, nil
+}
+
+// AppendGraph6 appends G in the given format (without a trailing newline).
+func AppendGraph6(out []byte, G *Graph, format Graph6Format) ([]byte, error) {
+	if format == Digraph6 {
+		return appendDigraph6(out, G)
+	}
+
+	idxOf, edges, err := graph6Indices(G)
+	if err != nil {
+		return nil, err
+	}
+	n := len(idxOf)
+
+	W := graph6Writer{out: out}
+	if format == Sparse6 {
+		W.out = append(W.out, ':')
+		W.writeSize(n)
+
+		sort.Slice(edges, func(i, j int) bool {
+			if edges[i][1] != edges[j][1] {
+				return edges[i][1] < edges[j][1]
+			}
+			return edges[i][0] < edges[j][0]
+		})
+		k := sparse6BitsPerVtx(n)
+		numBits := 0
+		v := 0
+		for _, e := range edges {
+			u, w := uint64(e[0]), e[1]
+			switch {
+			case w == v:
+				W.write(0, 1)
+				W.write(u, k)
+				numBits += 1 + k
+			case w == v+1:
+				W.write(1, 1)
+				W.write(u, k)
+				numBits += 1 + k
+				v = w
+			default:
+				W.write(1, 1)
+				W.write(uint64(w), k)
+				W.write(0, 1)
+				W.write(u, k)
+				numBits += 2 + 2*k
+				v = w
+			}
+		}
+
+		// Padding with 1 bits could otherwise be read as an edge in this special case; the condition is nauty's own
+		// (see sgtos6() in gtools.c) so that output matches it byte for byte.
+		padBits := (6 - numBits%6) % 6
+		if k < 6 && n == 1<<k && v == n-2 && padBits >= k+1 {
+			W.write(0, 1)
+		}
+		W.pad(1)
+		return W.out, nil
+	}
+
+	W.writeSize(n)
+	adj := make(map[[2]int]bool, len(edges))
+	for _, e := range edges {
+		adj[e] = true
+	}
+	for j := 1; j < n; j++ {
+		for i := 0; i < j; i++ {
+			bit := uint64(0)
+			if adj[[2]int{i, j}] {
+				bit = 1
+			}
+			W.write(bit, 1)
+		}
+	}
+	W.pad(0)
+	return W.out, nil
+}
+
+// appendDigraph6 reverses the arc subdivision described above.
+func appendDigraph6(out []byte, G *Graph) ([]byte, error) {
+	var labels []VtxLabel
+	isArcVtx := make(map[VtxLabel]bool)
+	for _, v := range G.Vtx {
+		switch v.Color {
+		case 0:
+			labels = append(labels, v.Label)
+		case ArcVtxColor:
+			isArcVtx[v.Label] = true
+		default:
+			return nil, errors.Wrapf(ErrBadGraph6, "vertex %d has a color not used by digraph6", v.Label)
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i] < labels[j]
+	})
+	idxOf := make(map[VtxLabel]int, len(labels))
+	for i, vi := range labels {
+		idxOf[vi] = i
+	}
+
+	tails := make(map[VtxLabel]int)
+	heads := make(map[VtxLabel]int)
+	for _, e := range G.Edges {
+		arcVtx, vtx := e.Va, e.Vb
+		if !isArcVtx[arcVtx] {
+			arcVtx, vtx = vtx, arcVtx
+		}
+		idx, found := idxOf[vtx]
+		if !isArcVtx[arcVtx] || !found {
+			return nil, errors.Wrapf(ErrBadGraph6, "edge %d-%d does not join a vertex to an arc vertex", e.Va, e.Vb)
+		}
+		var ends map[VtxLabel]int
+		switch e.Color {
+		case ArcTailColor:
+			ends = tails
+		case ArcHeadColor:
+			ends = heads
+		default:
+			return nil, errors.Wrapf(ErrBadGraph6, "edge %d-%d has a color not used by digraph6", e.Va, e.Vb)
+		}
+		if _, dupe := ends[arcVtx]; dupe {
+			return nil, errors.Wrapf(ErrBadGraph6, "arc vertex %d has multiple tails or heads", arcVtx)
+		}
+		ends[arcVtx] = idx
+	}
+
+	n := len(labels)
+	adj := make([]bool, n*n)
+	for arcVtx := range isArcVtx {
+		tail, hasTail := tails[arcVtx]
+		head, hasHead := heads[arcVtx]
+		if !hasTail || !hasHead || tail == head || adj[tail*n+head] {
+			return nil, errors.Wrapf(ErrBadGraph6, "arc vertex %d does not form a valid arc", arcVtx)
+		}
+		adj[tail*n+head] = true
+	}
+
+	W := graph6Writer{out: append(out, '&')}
+	W.writeSize(n)
+	for _, bit := range adj {
+		if bit {
+			W.write(1, 1)
+		} else {
+			W.write(0, 1)
+		}
+	}
+	W.pad(0)
+	return W.out, nil
+}
+
+// Graph6Reader reads successive graphs, one per line, in any mix of graph6, sparse6, and digraph6.
+type Graph6Reader struct {
+	scanner *bufio.Scanner
+	lineNum int
+}
+
+func NewGraph6Reader(r io.Reader) *Graph6Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 256*1024*1024)
+	return &Graph6Reader{
+		scanner: scanner,
+	}
+}
+
+// Next returns the next graph, skipping blank lines and returning io.EOF when there are no more graphs.
+func (R *Graph6Reader) Next() (*Graph, error) {
+	for R.scanner.Scan() {
+		R.lineNum++
+		line := bytes.TrimSpace(R.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		G, err := ParseGraph6(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", R.lineNum)
+		}
+		return G, nil
+	}
+	if err := R.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// ExportNext reads the next graph and sends it to Gout.  If an error is returned, nothing is sent.
+func (R *Graph6Reader) ExportNext(Gout GraphOut) error {
+	G, err := R.Next()
+	if err != nil {
+		return err
+	}
+	G.Export(Gout)
+	return nil
+}
+
+// Graph6Writer writes graphs, one per line, in a given format.
+type Graph6Writer struct {
+	w      io.Writer
+	format Graph6Format
+	buf    []byte
+}
+
+func NewGraph6Writer(w io.Writer, format Graph6Format) *Graph6Writer {
+	return &Graph6Writer{
+		w:      w,
+		format: format,
+	}
+}
+
+// Write writes G as a single line.
+func (W *Graph6Writer) Write(G *Graph) error {
+	var err error
+	W.buf, err = AppendGraph6(W.buf[:0], G, W.format)
+	if err != nil {
+		return err
+	}
+	W.buf = append(W.buf, '\n')
+	_, err = W.w.Write(W.buf)
+	return err
+}
+
+// WriteFrom consumes a graph from Gin and writes it as a single line.
+func (W *Graph6Writer) WriteFrom(Gin GraphIn) error {
+	return W.Write(ReadGraph(Gin))
+}
@@ -0,0 +1,73 @@
+package orca
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGraph6(t *testing.T) {
+	// The Petersen graph (from nauty's formats.txt examples) and K4 in each format
+	R := NewGraph6Reader(strings.NewReader("IheA@GUAo\n:Fa@x^\n&C]|w\n"))
+	for _, expected := range []struct {
+		Nv, Ne int
+		format Graph6Format
+	}{
+		{10, 15, Graph6},
+		{7, 4, Sparse6},
+		{4 + 12, 24, Digraph6},
+	} {
+		G, err := R.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(G.Vtx) != expected.Nv || len(G.Edges) != expected.Ne {
+			t.Fatalf("expected %d vtx and %d edges, got %d and %d", expected.Nv, expected.Ne, len(G.Vtx), len(G.Edges))
+		}
+
+		// Writing must reproduce a line that reads back as the same graph
+		line, err := AppendGraph6(nil, G, expected.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		G2, err := ParseGraph6(line)
+		if err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		line2, _ := AppendGraph6(nil, G2, expected.format)
+		if !bytes.Equal(line, line2) {
+			t.Fatalf("round trip mismatch: %s vs %s", line, line2)
+		}
+	}
+	if _, err := R.Next(); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+
+	if line, _ := AppendGraph6(nil, &Graph{Vtx: []Vtx{{Label: 1}, {Label: 2}}, Edges: []Edge{{Va: 1, Vb: 2}}}, Graph6); string(line) != "A_" {
+		t.Fatalf("expected A_, got %s", line)
+	}
+	if _, err := ParseGraph6([]byte(":AN")); err == nil {
+		t.Fatal("expected loop to be rejected")
+	}
+	if _, err := ParseGraph6([]byte(":~~@?????")); err == nil {
+		t.Fatal("expected a sparse6 header of 2^30 vertices to be rejected")
+	}
+}
+
+func TestSparse6Padding(t *testing.T) {
+	// n = 2^k cases where nauty pads with a leading 0 bit (last vertex n-2, k+1 padding bits) and where it doesn't
+	for _, line := range []string{":CcJ", ":Cf", ":Fa@x^"} {
+		G, err := ParseGraph6([]byte(line))
+		if err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		out, err := AppendGraph6(nil, G, Sparse6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != line {
+			t.Fatalf("expected %s, got %s", line, out)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package orca
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+/* GraphML:
+
+ReadGraphML reads the first <graph> of a GraphML document, mapping node and edge <data> to colors via the <key>
+whose attr.name matches the AttrMapping (including any <default> the key declares).  Node ids may be any string;
+vertices are labeled 1..N in document order.  Directed graphs, hyperedges and nested graphs are not supported.
+
+WriteGraphML writes a Canonic graph, so isomorphic graphs produce identical documents (other than the
+orca_orig node attribute, which records each vertex's label in the source graph).
+*/
+
+var ErrBadGraphML = errors.New("bad GraphML data")
+
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphmlKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+	Default  *struct {
+		Value string `xml:",chardata"`
+	} `xml:"default"`
+}
+
+type graphmlNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphmlData `xml:"data"`
+}
+
+type graphmlEdge struct {
+	Source   string        `xml:"source,attr"`
+	Target   string        `xml:"target,attr"`
+	Directed string        `xml:"directed,attr"`
+	Data     []graphmlData `xml:"data"`
+}
+
+type graphmlGraph struct {
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Data        []graphmlData `xml:"data"`
+	Nodes       []graphmlNode `xml:"node"`
+	Edges       []graphmlEdge `xml:"edge"`
+	HyperEdges  []struct{}    `xml:"hyperedge"`
+}
+
+type graphmlDoc struct {
+	XMLName xml.Name       `xml:"graphml"`
+	Keys    []graphmlKey   `xml:"key"`
+	Graphs  []graphmlGraph `xml:"graph"`
+}
+
+// graphmlAttr locates the key for the given attribute name and returns its id and default value.
+func (doc *graphmlDoc) attr(domain, attrName string) (id string, defaultVal string) {
+	if attrName == "" {
+		return "", ""
+	}
+	for _, key := range doc.Keys {
+		if key.AttrName == attrName && (key.For == domain || key.For == "all") {
+			if key.Default != nil {
+				defaultVal = key.Default.Value
+			}
+			return key.ID, defaultVal
+		}
+	}
+	return "", ""
+}
+
+func graphmlValue(data []graphmlData, keyID, defaultVal string) string {
+	if keyID != "" {
+		for _, di := range data {
+			if di.Key == keyID {
+				return di.Value
+			}
+		}
+	}
+	return defaultVal
+}
+
+// ReadGraphML reads a graph in GraphML, mapping attributes to colors via m.
+func ReadGraphML(r io.Reader, m AttrMapping) (*Graph, error) {
+	doc := graphmlDoc{}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, errors.Wrap(ErrBadGraphML, err.Error())
+	}
+	if len(doc.Graphs) == 0 {
+		return nil, errors.Wrap(ErrBadGraphML, "missing <graph>")
+	}
+	graph := &doc.Graphs[0]
+	if graph.EdgeDefault == "directed" {
+		return nil, errors.Wrap(ErrBadGraphML, "directed graphs are not supported")
+	}
+	if len(graph.HyperEdges) > 0 {
+		return nil, errors.Wrap(ErrBadGraphML, "hyperedges are not supported")
+	}
+
+	vtxKey, vtxDefault := doc.attr("node", m.VtxAttr)
+	edgeKey, edgeDefault := doc.attr("edge", m.EdgeAttr)
+
+	B := newIDGraphBuilder()
+	for _, node := range graph.Nodes {
+		var color VtxColor
+		if val := graphmlValue(node.Data, vtxKey, vtxDefault); val != "" {
+			var err error
+			if color, err = m.vtxColor(val); err != nil {
+				return nil, errors.Wrapf(ErrBadGraphML, "node %q: %v", node.ID, err)
+			}
+		}
+		if err := B.addVtx(node.ID, color); err != nil {
+			return nil, errors.Wrap(ErrBadGraphML, err.Error())
+		}
+	}
+	for _, edge := range graph.Edges {
+		if edge.Directed == "true" {
+			return nil, errors.Wrap(ErrBadGraphML, "directed edges are not supported")
+		}
+		var color EdgeColor
+		if val := graphmlValue(edge.Data, edgeKey, edgeDefault); val != "" {
+			var err error
+			if color, err = m.edgeColor(val); err != nil {
+				return nil, errors.Wrapf(ErrBadGraphML, "edge %q-%q: %v", edge.Source, edge.Target, err)
+			}
+		}
+		if err := B.addEdge(edge.Source, edge.Target, color); err != nil {
+			return nil, errors.Wrap(ErrBadGraphML, err.Error())
+		}
+	}
+
+	return &B.G, nil
+}
+
+// WriteGraphML writes C in GraphML, writing colors to the attributes named by m.
+func WriteGraphML(w io.Writer, C *Canonic, m AttrMapping) error {
+	out := bufio.NewWriter(w)
+	out.WriteString(xml.Header)
+	out.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+
+	// Named colors are written as strings, so the attr.type depends on the registry
+	attrType := func(registered bool) string {
+		if registered {
+			return "string"
+		}
+		return "long"
+	}
+	if m.VtxAttr != "" {
+		fmt.Fprintf(out, "  <key id=\"v\" for=\"node\" attr.name=\"%s\" attr.type=\"%s\"/>\n", xmlEscape(m.VtxAttr), attrType(m.VtxColors != nil))
+	}
+	if m.EdgeAttr != "" {
+		fmt.Fprintf(out, "  <key id=\"e\" for=\"edge\" attr.name=\"%s\" attr.type=\"%s\"/>\n", xmlEscape(m.EdgeAttr), attrType(m.EdgeColors != nil))
+	}
+	if C.Labeling != nil {
+		fmt.Fprintf(out, "  <key id=\"orig\" for=\"node\" attr.name=%q attr.type=\"long\"/>\n", CanonicOrigAttr)
+	}
+	fmt.Fprintf(out, "  <key id=\"canonic\" for=\"graph\" attr.name=%q attr.type=\"string\"/>\n", CanonicStringAttr)
+
+	out.WriteString("  <graph edgedefault=\"undirected\">\n")
+	fmt.Fprintf(out, "    <data key=\"canonic\">%s</data>\n", C.CanonicString())
+	for i, v := range C.Vtx {
+		fmt.Fprintf(out, "    <node id=\"n%d\">", v.Label)
+		if m.VtxAttr != "" {
+			fmt.Fprintf(out, "<data key=\"v\">%s</data>", xmlEscape(m.vtxValue(v.Color)))
+		}
+		if C.Labeling != nil {
+			fmt.Fprintf(out, "<data key=\"orig\">%d</data>", C.Labeling[i])
+		}
+		out.WriteString("</node>\n")
+	}
+	for _, e := range C.Edges {
+		fmt.Fprintf(out, "    <edge source=\"n%d\" target=\"n%d\">", e.Va, e.Vb)
+		if m.EdgeAttr != "" {
+			fmt.Fprintf(out, "<data key=\"e\">%s</data>", xmlEscape(m.edgeValue(e.Color)))
+		}
+		out.WriteString("</edge>\n")
+	}
+	out.WriteString("  </graph>\n</graphml>\n")
+	return out.Flush()
+}
+
+func xmlEscape(str string) string {
+	buf := strings.Builder{}
+	xml.EscapeText(&buf, []byte(str))
+	return buf.String()
+}
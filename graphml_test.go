@@ -0,0 +1,76 @@
+package orca
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGraphML(t *testing.T) {
+	m := AttrMapping{
+		VtxAttr:  "kind",
+		EdgeAttr: "weight",
+	}
+
+	// The same colored path, with nodes listed in a different order and one edge repeated
+	docs := []string{`<?xml version="1.0"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="kind" attr.type="long"><default>3</default></key>
+  <key id="d1" for="edge" attr.name="weight" attr.type="long"/>
+  <graph edgedefault="undirected">
+    <node id="a"><data key="d0">5</data></node>
+    <node id="b"/>
+    <node id="c"/>
+    <edge source="a" target="b"><data key="d1">2</data></edge>
+    <edge source="b" target="c"/>
+  </graph>
+</graphml>`, `<graphml>
+  <key id="k" for="all" attr.name="kind"/>
+  <key id="w" for="edge" attr.name="weight"/>
+  <graph edgedefault="undirected">
+    <node id="z"><data key="k">3</data></node>
+    <node id="y"><data key="k">3</data></node>
+    <node id="x"><data key="k">5</data></node>
+    <edge source="y" target="z"/>
+    <edge source="x" target="y"><data key="w">2</data></edge>
+    <edge source="y" target="x"><data key="w">2</data></edge>
+  </graph>
+</graphml>`}
+
+	var outputs []string
+	for _, doc := range docs {
+		G, err := ReadGraphML(strings.NewReader(doc), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(G.Vtx) != 3 || len(G.Edges) != 2 {
+			t.Fatalf("unexpected graph %v", G)
+		}
+		C, err := CanonizeGraph(NewCanonizer(DefaultCanonizerOpts), G)
+		if err != nil {
+			t.Fatal(err)
+		}
+		C.Labeling = nil
+		buf := &bytes.Buffer{}
+		if err = WriteGraphML(buf, C, m); err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, buf.String())
+
+		G2, err := ReadGraphML(buf, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		C2, _ := CanonizeGraph(NewCanonizer(DefaultCanonizerOpts), G2)
+		if C2.CanonicString() != C.CanonicString() {
+			t.Fatalf("round trip failed:\n%s", outputs[len(outputs)-1])
+		}
+	}
+	if outputs[0] != outputs[1] {
+		t.Fatalf("expected identical output:\n%s\n%s", outputs[0], outputs[1])
+	}
+
+	if _, err := ReadGraphML(strings.NewReader(`<graphml><graph edgedefault="directed"/></graphml>`), m); err == nil {
+		t.Fatal("expected directed graph error")
+	}
+}
@@ -0,0 +1,44 @@
+package orca
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+var ErrNotIsomorphic = errors.New("graphs are not isomorphic")
+
+// FindIsomorphism returns a bijection from the VtxLabels of Ga to those of Gb that preserves edges along with vertex
+// and edge colors, or ErrNotIsomorphic.
+//
+// The mapping is found by composing the two canonic labelings: the vertex of Ga assigned canonic label i maps to the
+// vertex of Gb assigned canonic label i.  Since this relies on the canonizer, graphs affected by its known issue with
+// ambiguous leaf order (see README) can be reported as not isomorphic.
+func FindIsomorphism(canonizer IGraphCanonizer, Ga, Gb *Graph) (map[VtxLabel]VtxLabel, error) {
+	if len(Ga.Vtx) != len(Gb.Vtx) || len(Ga.Edges) != len(Gb.Edges) {
+		return nil, ErrNotIsomorphic
+	}
+	Ca, err := CanonizeGraph(canonizer, Ga)
+	if err != nil {
+		return nil, err
+	}
+	Cb, err := CanonizeGraph(canonizer, Gb)
+	if err != nil {
+		return nil, err
+	}
+	return CanonicIsomorphism(Ca, Cb)
+}
+
+// CanonicIsomorphism is FindIsomorphism for graphs that are already canonized, such as when their canonic forms are
+// also needed.
+func CanonicIsomorphism(Ca, Cb *Canonic) (map[VtxLabel]VtxLabel, error) {
+	// Identical canonic encodings mean the relabeled graphs are identical, so the composed labeling is an isomorphism
+	if !bytes.Equal(Ca.Encoding(), Cb.Encoding()) {
+		return nil, ErrNotIsomorphic
+	}
+	mapping := make(map[VtxLabel]VtxLabel, len(Ca.Labeling))
+	for i, va := range Ca.Labeling {
+		mapping[va] = Cb.Labeling[i]
+	}
+	return mapping, nil
+}
@@ -0,0 +1,42 @@
+package orca
+
+import (
+	"testing"
+)
+
+func TestFindIsomorphism(t *testing.T) {
+	Ga, _ := ParseGraphText("v1:6 v2:6 v3:8 v4:7 1-(2)-2 2-3 2-4 4-5")
+	Gb, _ := ParseGraphText("v5:6 v3:6 v1:8 v2:7 5-(2)-3 3-1 3-2 2-4")
+
+	canonizer := NewCanonizer(DefaultCanonizerOpts)
+	mapping, err := FindIsomorphism(canonizer, Ga, Gb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	colorB := make(map[VtxLabel]VtxColor)
+	for _, v := range Gb.Vtx {
+		colorB[v.Label] = v.Color
+	}
+	for _, v := range Ga.Vtx {
+		if colorB[mapping[v.Label]] != v.Color {
+			t.Fatalf("vertex %d mapped to %d of a different color", v.Label, mapping[v.Label])
+		}
+	}
+	edgesB := make(map[CanonicalEdge]bool)
+	for _, e := range Gb.Edges {
+		edgesB[e.FormCanonicalEdge()] = true
+	}
+	for _, e := range Ga.Edges {
+		mapped := Edge{Va: mapping[e.Va], Vb: mapping[e.Vb], Color: e.Color}
+		if !edgesB[mapped.FormCanonicalEdge()] {
+			t.Fatalf("edge %v mapped to %v, which is not in Gb", e, mapped)
+		}
+	}
+
+	// Same shape, but the double bond moved
+	Gc, _ := ParseGraphText("v1:6 v2:6 v3:8 v4:7 1-2 2-(2)-3 2-4 4-5")
+	if _, err = FindIsomorphism(canonizer, Ga, Gc); err != ErrNotIsomorphic {
+		t.Fatal("expected ErrNotIsomorphic")
+	}
+}
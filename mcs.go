@@ -0,0 +1,308 @@
+package orca
+
+import (
+	"sort"
+	"time"
+)
+
+/* Maximum common subgraph:
+
+MaxCommonSubgraph finds a largest graph that is a subgraph of both Ga and Gb, respecting vertex and edge colors, via
+a branch and bound search over partial vertex mappings.  Vertices of Ga are visited in order of decreasing degree,
+and each is either mapped to a compatible unused vertex of Gb or left out.  A branch is pruned once the matches it can
+still add can't beat the best mapping found so far, which is kept so that a search cut short by its timeout still
+returns a result.
+
+    induced     maximize common vertices (then edges), where the edges among mapped vertices must agree exactly
+    edge        maximize common edges, where only vertices incident to a common edge are part of the result
+
+The common subgraph need not be connected.
+*/
+
+// MCSOpts controls MaxCommonSubgraph.
+type MCSOpts struct {
+	Induced   bool            // if set, find a maximum common induced subgraph, otherwise a maximum common edge subgraph
+	Timeout   time.Duration   // if non-zero, return the best found so far after this long
+	Canonizer IGraphCanonizer // canonizes the result (default: NewCanonizer(DefaultCanonizerOpts))
+}
+
+// CommonSubgraph is a common subgraph of two graphs in canonic form, along with where it occurs in each.
+type CommonSubgraph struct {
+	Canonic
+
+	// MappingA[i] and MappingB[i] are the VtxLabels of Ga and Gb matched to canonic VtxLabel i+1.
+	MappingA []VtxLabel
+	MappingB []VtxLabel
+
+	// Complete is false if the search timed out, in which case a larger common subgraph may exist.
+	Complete bool
+}
+
+type mcsScore struct {
+	primary   int // vertices if induced, otherwise edges
+	secondary int // edges if induced
+}
+
+func (s mcsScore) beats(other mcsScore) bool {
+	return s.primary > other.primary || (s.primary == other.primary && s.secondary > other.secondary)
+}
+
+type mcsSearch struct {
+	opts     MCSOpts
+	A, B     *matchGraph
+	order    []int // A vertices in visit order
+	laterDeg []int // laterDeg[k] is the number of A edges with an endpoint at order position k or later
+	mapped   []int // A vertex => B vertex (or -1)
+	owner    []int // B vertex => A vertex (or -1)
+	remainA  map[VtxColor]int
+	unusedB  map[VtxColor]int
+	score    mcsScore
+	best     []int
+	bestScr  mcsScore
+	deadline time.Time
+	nodes    int
+	timedOut bool
+}
+
+// MaxCommonSubgraph returns a maximum common subgraph of Ga and Gb (see MCSOpts).
+func MaxCommonSubgraph(Ga, Gb *Graph, opts MCSOpts) (*CommonSubgraph, error) {
+	A, err := newMatchGraph(Ga)
+	if err != nil {
+		return nil, err
+	}
+	B, err := newMatchGraph(Gb)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Canonizer == nil {
+		opts.Canonizer = NewCanonizer(DefaultCanonizerOpts)
+	}
+
+	s := &mcsSearch{
+		opts:    opts,
+		A:       A,
+		B:       B,
+		mapped:  make([]int, len(A.labels)),
+		owner:   make([]int, len(B.labels)),
+		remainA: make(map[VtxColor]int),
+		unusedB: make(map[VtxColor]int),
+	}
+	for i := range s.mapped {
+		s.mapped[i] = -1
+		s.remainA[A.colors[i]]++
+	}
+	for i := range s.owner {
+		s.owner[i] = -1
+		s.unusedB[B.colors[i]]++
+	}
+	s.best = append([]int(nil), s.mapped...)
+	if opts.Timeout > 0 {
+		s.deadline = time.Now().Add(opts.Timeout)
+	}
+
+	s.order = make([]int, len(A.labels))
+	for i := range s.order {
+		s.order[i] = i
+	}
+	sort.SliceStable(s.order, func(i, j int) bool {
+		return len(A.adj[s.order[i]]) > len(A.adj[s.order[j]])
+	})
+	pos := make([]int, len(s.order))
+	for k, va := range s.order {
+		pos[va] = k
+	}
+	s.laterDeg = make([]int, len(s.order)+1)
+	for va, adj := range A.adj {
+		for wa := range adj {
+			// Count each edge once, at its later endpoint
+			if pos[wa] < pos[va] {
+				s.laterDeg[pos[va]]++
+			}
+		}
+	}
+	for k := len(s.order) - 1; k >= 0; k-- {
+		s.laterDeg[k] += s.laterDeg[k+1]
+	}
+
+	s.search(0)
+	return s.result()
+}
+
+// bound returns an upper bound on the score reachable from order position k.
+func (s *mcsSearch) bound(k int) mcsScore {
+	bound := s.score
+	if s.opts.Induced {
+		for color, count := range s.remainA {
+			bound.primary += min(count, s.unusedB[color])
+		}
+		bound.secondary += s.laterDeg[k]
+	} else {
+		bound.primary += s.laterDeg[k]
+	}
+	return bound
+}
+
+func (s *mcsSearch) expired() bool {
+	if s.timedOut {
+		return true
+	}
+	s.nodes++
+	if !s.deadline.IsZero() && s.nodes%1024 == 0 && time.Now().After(s.deadline) {
+		s.timedOut = true
+	}
+	return s.timedOut
+}
+
+func (s *mcsSearch) search(k int) {
+	if s.score.beats(s.bestScr) {
+		s.bestScr = s.score
+		copy(s.best, s.mapped)
+	}
+	if k == len(s.order) || s.expired() || !s.bound(k).beats(s.bestScr) {
+		return
+	}
+
+	va := s.order[k]
+	color := s.A.colors[va]
+	s.remainA[color]--
+
+	// Try candidates with the most common edges first so good mappings are found early
+	type candidate struct {
+		vb   int
+		gain int
+	}
+	var candidates []candidate
+	for vb, owner := range s.owner {
+		if owner >= 0 || s.B.colors[vb] != color {
+			continue
+		}
+		if gain, ok := s.gain(va, vb); ok {
+			candidates = append(candidates, candidate{vb, gain})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].gain > candidates[j].gain
+	})
+
+	for _, c := range candidates {
+		prev := s.score
+		if s.opts.Induced {
+			s.score.primary++
+			s.score.secondary += c.gain
+		} else {
+			s.score.primary += c.gain
+		}
+		s.mapped[va] = c.vb
+		s.owner[c.vb] = va
+		s.unusedB[color]--
+		s.search(k + 1)
+		s.unusedB[color]++
+		s.owner[c.vb] = -1
+		s.mapped[va] = -1
+		s.score = prev
+		if s.timedOut {
+			break
+		}
+	}
+
+	// Leave va out
+	if !s.timedOut {
+		s.search(k + 1)
+	}
+	s.remainA[color]++
+}
+
+// gain returns the number of common edges added by mapping va to vb, and if induced, whether the mapping agrees with
+// all mapped vertices.
+func (s *mcsSearch) gain(va, vb int) (int, bool) {
+	gain := 0
+	for wa, colorA := range s.A.adj[va] {
+		wb := s.mapped[wa]
+		if wb < 0 {
+			continue
+		}
+		colorB, adjacent := s.B.adj[vb][wb]
+		if adjacent && colorA == colorB {
+			gain++
+		} else if s.opts.Induced {
+			return 0, false
+		}
+	}
+	if s.opts.Induced {
+		for wb := range s.B.adj[vb] {
+			if s.owner[wb] >= 0 {
+				if _, adjacent := s.A.adj[va][s.owner[wb]]; !adjacent {
+					return 0, false
+				}
+			}
+		}
+	}
+	return gain, true
+}
+
+// result builds and canonizes the common subgraph of the best mapping.
+func (s *mcsSearch) result() (*CommonSubgraph, error) {
+	var common []int // A vertices in the common subgraph
+	var edges []Edge
+	idxOf := make(map[int]VtxLabel)
+	for va, vb := range s.best {
+		if vb >= 0 {
+			for wa, colorA := range s.A.adj[va] {
+				wb := s.best[wa]
+				if wb < 0 || wa < va {
+					continue
+				}
+				if colorB, adjacent := s.B.adj[vb][wb]; adjacent && colorA == colorB {
+					edges = append(edges, Edge{Va: VtxLabel(va), Vb: VtxLabel(wa), Color: colorA})
+				}
+			}
+		}
+	}
+	include := func(va int) {
+		if _, exists := idxOf[va]; !exists {
+			common = append(common, va)
+			idxOf[va] = VtxLabel(len(common))
+		}
+	}
+	for va, vb := range s.best {
+		if vb >= 0 && s.opts.Induced {
+			include(va)
+		}
+	}
+	for _, e := range edges {
+		include(int(e.Va))
+		include(int(e.Vb))
+	}
+
+	// Label the common subgraph 1..N for the canonizer
+	G := &Graph{
+		Vtx:   make([]Vtx, len(common)),
+		Edges: make([]Edge, len(edges)),
+	}
+	for i, va := range common {
+		G.Vtx[i] = Vtx{Label: VtxLabel(i + 1), Color: s.A.colors[va]}
+	}
+	for i, e := range edges {
+		G.Edges[i] = Edge{Va: idxOf[int(e.Va)], Vb: idxOf[int(e.Vb)], Color: e.Color}
+	}
+
+	CS := &CommonSubgraph{
+		Complete: !s.timedOut,
+	}
+	if len(common) == 0 {
+		return CS, nil
+	}
+	C, err := CanonizeGraph(s.opts.Canonizer, G)
+	if err != nil {
+		return nil, err
+	}
+	CS.Canonic = *C
+	CS.MappingA = make([]VtxLabel, len(C.Labeling))
+	CS.MappingB = make([]VtxLabel, len(C.Labeling))
+	for i, vi := range C.Labeling {
+		va := common[vi-1]
+		CS.MappingA[i] = s.A.labels[va]
+		CS.MappingB[i] = s.B.labels[s.best[va]]
+	}
+	return CS, nil
+}
@@ -0,0 +1,84 @@
+package orca
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMaxCommonSubgraph(t *testing.T) {
+	parse := func(text string) *Graph {
+		G, err := ParseGraphText(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return G
+	}
+	checkMappings := func(CS *CommonSubgraph, Ga, Gb *Graph) {
+		for _, e := range CS.Edges {
+			for _, G := range []*Graph{Ga, Gb} {
+				mapping := CS.MappingA
+				if G == Gb {
+					mapping = CS.MappingB
+				}
+				found := false
+				for _, f := range G.Edges {
+					va, vb := mapping[e.Va-1], mapping[e.Vb-1]
+					if ((f.Va == va && f.Vb == vb) || (f.Va == vb && f.Vb == va)) && f.Color == e.Color {
+						found = true
+					}
+				}
+				if !found {
+					t.Fatalf("common edge %d-%d is missing from an input", e.Va, e.Vb)
+				}
+			}
+		}
+	}
+
+	// A triangle with a tail vs a square: the edge MCS is a path of 3 edges, the induced MCS a path of 3 vertices
+	Ga := parse("1-2 2-3 3-1 3-4")
+	Gb := parse("1-2 2-3 3-4 4-1")
+	CS, err := MaxCommonSubgraph(Ga, Gb, MCSOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !CS.Complete || CS.NumEdges() != 3 || CS.NumVerts() != 4 {
+		t.Fatalf("unexpected edge MCS %v", CS.Graph.String())
+	}
+	checkMappings(CS, Ga, Gb)
+
+	CS, err = MaxCommonSubgraph(Ga, Gb, MCSOpts{Induced: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if CS.NumVerts() != 3 || CS.NumEdges() != 2 {
+		t.Fatalf("unexpected induced MCS %v", CS.Graph.String())
+	}
+	checkMappings(CS, Ga, Gb)
+
+	// Colors: only the C=O bond is common
+	Ga = parse("v1:6 v2:8 v3:7 1-(2)-2 1-3")
+	Gb = parse("v1:8 v2:6 v3:8 1-(2)-2 2-3")
+	CS, _ = MaxCommonSubgraph(Ga, Gb, MCSOpts{})
+	checkMappings(CS, Ga, Gb)
+	if CS.NumEdges() != 1 || CS.Edges[0].Color != 2 {
+		t.Fatalf("unexpected colored MCS %v", CS.Graph.String())
+	}
+
+	// Disjoint colors give an empty result
+	CS, _ = MaxCommonSubgraph(parse("v1:1 v2:1 1-2"), parse("1-2"), MCSOpts{})
+	if CS.NumVerts() != 0 || !CS.Complete {
+		t.Fatal("expected an empty common subgraph")
+	}
+
+	// A timeout returns the best so far
+	var big1, big2 string
+	for i := 1; i < 40; i++ {
+		big1 += fmt.Sprintf(" %d-%d %d-%d", i, i+1, i, (i+6)%40+1)
+		big2 += fmt.Sprintf(" %d-%d %d-%d", i, i+1, i, (i+9)%40+1)
+	}
+	CS, err = MaxCommonSubgraph(parse(big1), parse(big2), MCSOpts{Timeout: 20 * time.Millisecond})
+	if err != nil || CS.Complete || CS.NumEdges() < 39 {
+		t.Fatalf("expected an incomplete result of at least a path, got %d edges (%v)", CS.NumEdges(), err)
+	}
+}
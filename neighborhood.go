@@ -0,0 +1,63 @@
+package orca
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+
+	"github.com/pkg/errors"
+)
+
+// NeighborhoodHashes -- see NeighborhoodHashes()
+func (ctx *encoderCtx) NeighborhoodHashes(radius int) (map[VtxLabel][]uint64, error) {
+	if ctx.Error() != nil {
+		return nil, ctx.Error()
+	}
+	if radius < 0 {
+		return nil, errors.Errorf("radius %d must be >= 0", radius)
+	}
+
+	ctx.surfaceSort()
+	subG := ctx.SelfSubGraph().(*subGraph)
+
+	var lenBuf [binary.MaxVarintLen64]byte
+	var encScrap [256]byte
+	hashes := make(map[VtxLabel][]uint64, ctx.NumVerts())
+	for _, v := range ctx.vtx {
+		if ctx.removedVtx[v.VtxLabel] {
+			continue
+		}
+
+		// The hash at each radius covers the blocks of every depth up to it, where a block is nil once the dag is
+		// complete, so a neighborhood's hash stops changing once it spans the vertex's component
+		h := fnv.New64a()
+		vtxHashes := make([]uint64, radius+1)
+		for depth := 0; depth <= radius; depth++ {
+			block := ctx.ExportCanonicBlock(subG, v.VtxLabel, depth, encScrap[:0])
+			if block != nil {
+				h.Write(lenBuf[:binary.PutUvarint(lenBuf[:], uint64(len(block)))])
+				h.Write(block)
+			}
+			vtxHashes[depth] = h.Sum64()
+		}
+		hashes[v.VtxLabel] = vtxHashes
+	}
+	return hashes, nil
+}
+
+// NeighborhoodHashes builds G with the given canonizer and returns, for each vertex, a hash of its canonical
+// neighborhood at each radius 0..radius, i.e. of the canonic blocks of the dag rooted at the vertex up to that depth
+// (see ExportCanonicBlock).  Equal hashes mean (barring collisions) isomorphic neighborhoods within and across
+// graphs, making them suitable as atom environments and ECFP-like fingerprint features.
+func NeighborhoodHashes(canonizer IGraphCanonizer, G *Graph, radius int) (map[VtxLabel][]uint64, error) {
+	ctx, err := encoderFor(canonizer)
+	if err != nil {
+		return nil, err
+	}
+	Gin, Gout := NewGraphIO()
+	go G.Export(Gout)
+
+	if err = ctx.BuildGraph(Gin); err != nil {
+		return nil, err
+	}
+	return ctx.NeighborhoodHashes(radius)
+}
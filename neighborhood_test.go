@@ -0,0 +1,54 @@
+package orca
+
+import (
+	"testing"
+)
+
+func TestNeighborhoodHashes(t *testing.T) {
+	canonizer := NewCanonizer(DefaultCanonizerOpts)
+	hashes := func(text string) map[VtxLabel][]uint64 {
+		G, err := ParseGraphText(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		H, err := NeighborhoodHashes(canonizer, G, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(H) != len(G.Vtx) {
+			t.Fatalf("expected a hash for each vertex")
+		}
+		return H
+	}
+
+	// Every vertex of a ring is alike
+	ring := hashes("1-2 2-3 3-4 4-5 5-6 6-1")
+	for vi := VtxLabel(2); vi <= 6; vi++ {
+		for r := 0; r <= 3; r++ {
+			if ring[vi][r] != ring[1][r] {
+				t.Fatalf("expected ring vertices to match at radius %d", r)
+			}
+		}
+	}
+
+	// In a path, the ends match each other and the middle is distinct, regardless of labeling
+	path := hashes("1-2 2-3 3-4 4-5")
+	if path[1][3] != path[5][3] || path[2][3] != path[4][3] || path[3][1] == path[1][1] {
+		t.Fatal("unexpected path hashes")
+	}
+	relabeled := hashes("3-5 5-1 1-4 4-2")
+	if relabeled[3][3] != path[1][3] || relabeled[1][3] != path[3][3] {
+		t.Fatal("expected hashes to be independent of labeling")
+	}
+
+	// A path's middle and a ring vertex look alike up close but not from further away
+	if path[3][0] != ring[1][0] || path[3][1] != ring[1][1] || path[3][3] == ring[1][3] {
+		t.Fatal("unexpected path vs ring hashes")
+	}
+
+	// Colors are part of a neighborhood
+	colored := hashes("v3:8 1-2 2-3 3-4 4-5")
+	if colored[1][1] != path[1][1] || colored[1][2] == path[1][2] {
+		t.Fatal("expected a colored vertex two away to be seen at radius 2")
+	}
+}
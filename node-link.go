@@ -0,0 +1,171 @@
+package orca
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+/* Node-link JSON:
+
+This is the format of NetworkX's node_link_data() and node_link_graph():
+
+    {"directed": false, "multigraph": false, "graph": {},
+     "nodes": [{"id": 1, "color": 6}, ...],
+     "links": [{"source": 1, "target": 2, "order": 2}, ...]}
+
+Node ids may be any JSON value; vertices are labeled 1..N in the order of "nodes".  Newer NetworkX versions may
+name the edge list "edges" rather than "links", so either is accepted.  Directed graphs and multigraphs are not
+supported.
+*/
+
+var ErrBadNodeLink = errors.New("bad node-link JSON data")
+
+type nodeLinkDoc struct {
+	Directed   bool                         `json:"directed"`
+	Multigraph bool                         `json:"multigraph"`
+	Graph      map[string]interface{}       `json:"graph"`
+	Nodes      []map[string]json.RawMessage `json:"nodes"`
+	Links      []map[string]json.RawMessage `json:"links"`
+	Edges      []map[string]json.RawMessage `json:"edges,omitempty"`
+}
+
+// nodeLinkValue returns the given attribute as a string (a JSON string is unquoted), or "" if absent or null.
+func nodeLinkValue(attrs map[string]json.RawMessage, attrName string) (string, error) {
+	if attrName == "" {
+		return "", nil
+	}
+	raw := bytes.TrimSpace(attrs[attrName])
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	if raw[0] == '"' {
+		var str string
+		err := json.Unmarshal(raw, &str)
+		return str, err
+	}
+	return string(raw), nil
+}
+
+// nodeLinkID returns a node id in a form suitable as a map key.
+func nodeLinkID(attrs map[string]json.RawMessage, attrName string) (string, error) {
+	raw := attrs[attrName]
+	if len(raw) == 0 {
+		return "", errors.Errorf("missing %q", attrName)
+	}
+	buf := bytes.Buffer{}
+	if err := json.Compact(&buf, raw); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// ReadNodeLink reads a graph in node-link JSON, mapping attributes to colors via m.
+func ReadNodeLink(r io.Reader, m AttrMapping) (*Graph, error) {
+	doc := nodeLinkDoc{}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, errors.Wrap(ErrBadNodeLink, err.Error())
+	}
+	if doc.Directed || doc.Multigraph {
+		return nil, errors.Wrap(ErrBadNodeLink, "directed graphs and multigraphs are not supported")
+	}
+	if doc.Nodes == nil {
+		return nil, errors.Wrap(ErrBadNodeLink, "missing \"nodes\"")
+	}
+
+	B := newIDGraphBuilder()
+	for i, node := range doc.Nodes {
+		id, err := nodeLinkID(node, "id")
+		if err != nil {
+			return nil, errors.Wrapf(ErrBadNodeLink, "node %d: %v", i, err)
+		}
+		var color VtxColor
+		val, err := nodeLinkValue(node, m.VtxAttr)
+		if err == nil && val != "" {
+			color, err = m.vtxColor(val)
+		}
+		if err == nil {
+			err = B.addVtx(id, color)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(ErrBadNodeLink, "node %s: %v", id, err)
+		}
+	}
+
+	links := doc.Links
+	if links == nil {
+		links = doc.Edges
+	}
+	for i, link := range links {
+		source, err := nodeLinkID(link, "source")
+		if err != nil {
+			return nil, errors.Wrapf(ErrBadNodeLink, "link %d: %v", i, err)
+		}
+		target, err := nodeLinkID(link, "target")
+		if err != nil {
+			return nil, errors.Wrapf(ErrBadNodeLink, "link %d: %v", i, err)
+		}
+		var color EdgeColor
+		val, err := nodeLinkValue(link, m.EdgeAttr)
+		if err == nil && val != "" {
+			color, err = m.edgeColor(val)
+		}
+		if err == nil {
+			err = B.addEdge(source, target, color)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(ErrBadNodeLink, "link %d: %v", i, err)
+		}
+	}
+
+	return &B.G, nil
+}
+
+// WriteNodeLink writes C in node-link JSON, writing colors to the attributes named by m.  Node ids are the canonic
+// VtxLabels, and the source graph labels and canonic string are written as for WriteGraphML.
+func WriteNodeLink(w io.Writer, C *Canonic, m AttrMapping) error {
+	doc := nodeLinkDoc{
+		Graph: map[string]interface{}{
+			CanonicStringAttr: C.CanonicString(),
+		},
+		Nodes: make([]map[string]json.RawMessage, len(C.Vtx)),
+		Links: make([]map[string]json.RawMessage, len(C.Edges)),
+	}
+
+	// Named colors are written as JSON strings, otherwise as numbers
+	colorValue := func(val string) json.RawMessage {
+		if _, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return json.RawMessage(val)
+		}
+		str, _ := json.Marshal(val)
+		return str
+	}
+
+	for i, v := range C.Vtx {
+		node := map[string]json.RawMessage{
+			"id": json.RawMessage(strconv.FormatUint(uint64(v.Label), 10)),
+		}
+		if m.VtxAttr != "" {
+			node[m.VtxAttr] = colorValue(m.vtxValue(v.Color))
+		}
+		if C.Labeling != nil {
+			node[CanonicOrigAttr] = json.RawMessage(strconv.FormatUint(uint64(C.Labeling[i]), 10))
+		}
+		doc.Nodes[i] = node
+	}
+	for i, e := range C.Edges {
+		link := map[string]json.RawMessage{
+			"source": json.RawMessage(strconv.FormatUint(uint64(e.Va), 10)),
+			"target": json.RawMessage(strconv.FormatUint(uint64(e.Vb), 10)),
+		}
+		if m.EdgeAttr != "" {
+			link[m.EdgeAttr] = colorValue(m.edgeValue(e.Color))
+		}
+		doc.Links[i] = link
+	}
+
+	return json.NewEncoder(w).Encode(&doc)
+}
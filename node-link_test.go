@@ -0,0 +1,57 @@
+package orca
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNodeLink(t *testing.T) {
+	vtxColors := NewVtxColorRegistry()
+	if err := vtxColors.AddVtxColors([]VtxColorDef{
+		{NameAscii: "red", VtxColor: 1},
+		{NameAscii: "blue", VtxColor: 2},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	m := AttrMapping{
+		VtxAttr:   "color",
+		EdgeAttr:  "order",
+		VtxColors: vtxColors,
+	}
+
+	G, err := ReadNodeLink(strings.NewReader(`{"directed": false, "multigraph": false, "graph": {},
+		"nodes": [{"id": "p"}, {"id": 7, "color": "blue"}, {"id": [1, 2], "color": 1}],
+		"edges": [{"source": "p", "target": 7, "order": 2}, {"source": 7, "target": [1,2]}]}`), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(G.Vtx) != 3 || len(G.Edges) != 2 || G.Vtx[1].Color != 2 || G.Vtx[2].Color != 1 || G.Edges[0].Color != 2 {
+		t.Fatalf("unexpected graph %v", G)
+	}
+
+	C, err := CanonizeGraph(NewCanonizer(DefaultCanonizerOpts), G)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	if err = WriteNodeLink(buf, C, m); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"color":"blue"`) || !strings.Contains(buf.String(), C.CanonicString()) {
+		t.Fatalf("unexpected output %s", buf.String())
+	}
+
+	G2, err := ReadNodeLink(buf, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	C2, _ := CanonizeGraph(NewCanonizer(DefaultCanonizerOpts), G2)
+	if C2.CanonicString() != C.CanonicString() {
+		t.Fatal("round trip failed")
+	}
+
+	if _, err = ReadNodeLink(strings.NewReader(`{"nodes": [{"id": 1, "color": "green"}], "links": []}`), m); err == nil {
+		t.Fatal("expected unknown color error")
+	}
+}
@@ -23,6 +23,7 @@ type encoderCtx struct {
 
 	Opts           CanonizerOpts
 	encodingLookup redblacktree.Tree // maps []byte (a subgraph encoding) => encodingID
+	canonicRoots   []VtxLabel        // canonic root of each connected component (in canonic order)
 }
 
 
@@ -31,7 +32,7 @@ func newEncoder(opts CanonizerOpts) *encoderCtx {
     ctx := &encoderCtx{
         Opts: opts,
     }
-    ctx.init(SubGraphPool(ctx))
+    ctx.init(SubGraphPool(ctx), opts.VtxColors, opts.EdgeColors)
     return ctx
 }
 
@@ -84,23 +85,145 @@ type vtxRange struct {
 func (ctx *encoderCtx) Canonize(Gout GraphOut) {
         
     if ctx.Error() != nil {
+        Gout.Break()
         return
     }
 
+    subG := ctx.canonize()
+    
+    offset := VtxLabel(0)
+    for _, root := range ctx.canonicRoots {
+        ctx.ExportCanonic(subG, root, offset, Gout)
+        offset += VtxLabel(len(subG.dagFromVtx[root].vtx))
+    }
+    Gout.Break()
+}
+
+
+// CanonicLabeling returns the canonic labeling of the graph the canonizer most recently built, where labeling[i] is
+// the VtxLabel (as built) that Canonize assigns canonic VtxLabel i+1.
+func CanonicLabeling(canonizer IGraphCanonizer) ([]VtxLabel, error) {
+    ctx, err := encoderFor(canonizer)
+    if err != nil {
+        return nil, err
+    }
+    return ctx.CanonicLabeling()
+}
+
+
+// CanonicLabeling -- see CanonicLabeling()
+func (ctx *encoderCtx) CanonicLabeling() ([]VtxLabel, error) {
+    if ctx.Error() != nil {
+        return nil, ctx.Error()
+    }
+    
+    subG := ctx.canonize()
+    
+    labeling := make([]VtxLabel, 0, ctx.NumVerts())
+    for _, root := range ctx.canonicRoots {
+        for _, vi := range subG.dagFromVtx[root].vtx {
+            labeling = append(labeling, vi.VtxLabel)
+        }
+    }
+    return labeling, nil
+}
+
+
+// canonize chooses a canonic root for each connected component and canonically orders the components, placing the result in ctx.canonicRoots.
+func (ctx *encoderCtx) canonize() *subGraph {
+
     ctx.resetCtx()
 
     subG := ctx.SelfSubGraph().(*subGraph)
     
     // First, do a surface canonic sort and see we can we canonically identify.
     // Vtx are sorted such that higher degree vtx appear
-    Nv := ctx.NumVerts()
+    ctx.surfaceSort()
     vtx := ctx.vtx
     
+    // After edits, the surface sort must only see the vertices and edges that remain
+    if ctx.editMask != nil {
+        vtx = ctx.presentVtx(subG)
+        canonicSort(vtx)
+    }
+    
+    // A dag only reaches the vertices connected to its root, so each connected component gets its own root.
+    ctx.canonicRoots = ctx.canonicRoots[:0]
+    for _, comp := range connectedComponents(vtx) {
+        root := ctx.findComponentRoot(subG, comp)
+        dag := ctx.dagForRootVtx(subG, root)
+        for !dag.canonicComplete {
+            ctx.canonizeNextDepth(subG, dag)
+        }
+        ctx.canonicRoots = append(ctx.canonicRoots, root)
+    }
+    
+    // Order components by their complete encoding so that component order is also canonic.
+    if len(ctx.canonicRoots) > 1 {
+        blocks := make([][]byte, len(ctx.canonicRoots))
+        for i, root := range ctx.canonicRoots {
+            blocks[i] = ctx.encodeCanonicBlock(subG.dagFromVtx[root], subG.dagFromVtx[root].vtx, nil)
+        }
+        sort.Sort(rootsByBlock{ctx.canonicRoots, blocks})
+    }
+    
+    return subG
+}
+
+
+// surfaceSort canonic sorts ctx.vtx (and each vertex's edges, which sets the order dags traverse them).
+func (ctx *encoderCtx) surfaceSort() {
     canonicSort(ctx.vtx)
-    for i := range vtx {
-        ctx.vtxIndex[vtx[i].VtxLabel] = uint32(i)
+    for i := range ctx.vtx {
+        ctx.vtxIndex[ctx.vtx[i].VtxLabel] = uint32(i)
+    }
+}
+
+
+// connectedComponents partitions vtx into connected components, retaining the (canonic sorted) order of vtx within each component.
+func connectedComponents(vtx []dagVtx) [][]dagVtx {
+    Nv := len(vtx)
+    compOf := make([]int, Nv)
+    vtxIndex := make(map[VtxLabel]uint32, Nv)
+    for i := range compOf {
+        compOf[i] = -1
+        vtxIndex[vtx[i].VtxLabel] = uint32(i)
     }
     
+    numComps := 0
+    var stack []uint32
+    for i := 0; i < Nv; i++ {
+        if compOf[i] >= 0 {
+            continue
+        }
+        compOf[i] = numComps
+        stack = append(stack[:0], uint32(i))
+        for len(stack) > 0 {
+            vi := stack[len(stack)-1]
+            stack = stack[:len(stack)-1]
+            for _, edge := range vtx[vi].edges {
+                vj := vtxIndex[edge.toVtx]
+                if compOf[vj] < 0 {
+                    compOf[vj] = numComps
+                    stack = append(stack, vj)
+                }
+            }
+        }
+        numComps++
+    }
+    
+    comps := make([][]dagVtx, numComps)
+    for i, vi := range vtx {
+        comps[compOf[i]] = append(comps[compOf[i]], vi)
+    }
+    return comps
+}
+
+
+// findComponentRoot returns the canonic root of the given component, where vtx[] is in canonic sorted order.
+func (ctx *encoderCtx) findComponentRoot(subG *subGraph, vtx []dagVtx) VtxLabel {
+    Nv := len(vtx)
+    
     rankSpan := vtxRange{0, Nv}
     runLen := 1
     
@@ -137,16 +260,26 @@ func (ctx *encoderCtx) Canonize(Gout GraphOut) {
         }
     }
         
-    canonicRoot := ctx.findCanonicRoot(toRank)
-    
-    ctx.ExportCanonic(subG, canonicRoot, Gout)
+    return ctx.findCanonicRoot(subG, toRank)
+}
+
 
+// rootsByBlock sorts canonic roots by the encoding of their dags.
+type rootsByBlock struct {
+    roots  []VtxLabel
+    blocks [][]byte
 }
 
+func (R rootsByBlock) Len() int           { return len(R.roots) }
+func (R rootsByBlock) Less(i, j int) bool { return bytes.Compare(R.blocks[i], R.blocks[j]) < 0 }
+func (R rootsByBlock) Swap(i, j int) {
+    R.roots[i], R.roots[j] = R.roots[j], R.roots[i]
+    R.blocks[i], R.blocks[j] = R.blocks[j], R.blocks[i]
+}
 
-func (ctx *encoderCtx) findCanonicRoot(toRank []vtxToRank) VtxLabel {
 
-    subG := ctx.SelfSubGraph().(*subGraph)
+func (ctx *encoderCtx) findCanonicRoot(subG *subGraph, toRank []vtxToRank) VtxLabel {
+
     L := 0
     R := len(toRank)-1
 
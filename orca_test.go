@@ -184,4 +184,66 @@ func testPrism(numFace1Verts, numFace2Verts int) {
 }
 
 
-// func test
\ No newline at end of file
+// func test
+
+func TestCanonizeComponents(t *testing.T) {
+	// A triangle, a colored path, and an isolated vertex, built under two labelings
+	genGraph := func(relabel func(VtxLabel) VtxLabel) func(Gout GraphOut) {
+		return func(Gout GraphOut) {
+			for vi := VtxLabel(1); vi <= 6; vi++ {
+				color := VtxColor(0)
+				if vi >= 4 {
+					color = VtxColor(vi)
+				}
+				Gout.Vtx <- Vtx{Label: relabel(vi), Color: color}
+			}
+			for _, e := range []Edge{{1, 2, 0}, {2, 3, 0}, {3, 1, 0}, {4, 5, 7}} {
+				Gout.Edges <- Edge{Va: relabel(e.Va), Vb: relabel(e.Vb), Color: e.Color}
+			}
+			Gout.Break()
+		}
+	}
+
+	ctx := NewCanonizer(DefaultCanonizerOpts)
+	canonize := func(gen func(Gout GraphOut)) ([]Vtx, []Edge, []VtxLabel) {
+		Gin, Gout := NewGraphIO()
+		go gen(Gout)
+		if err := ctx.BuildGraph(Gin); err != nil {
+			t.Fatal(err)
+		}
+		labeling, err := CanonicLabeling(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		Gin, Gout = NewGraphIO()
+		go ctx.Canonize(Gout)
+		var vtx []Vtx
+		var edges []Edge
+		Gin.Consume(func(v Vtx, e Edge) {
+			if v.Label != 0 {
+				vtx = append(vtx, v)
+			} else {
+				edges = append(edges, e)
+			}
+		})
+		return vtx, edges, labeling
+	}
+
+	vtx1, edges1, labeling1 := canonize(genGraph(func(vi VtxLabel) VtxLabel { return vi }))
+	vtx2, edges2, labeling2 := canonize(genGraph(func(vi VtxLabel) VtxLabel { return 7 - vi }))
+	if fmt.Sprint(vtx1, edges1) != fmt.Sprint(vtx2, edges2) {
+		t.Fatalf("relabeled graph gave a different canonic form:\n%v %v\n%v %v", vtx1, edges1, vtx2, edges2)
+	}
+	for _, labeling := range [][]VtxLabel{labeling1, labeling2} {
+		seen := make(map[VtxLabel]bool)
+		for _, vi := range labeling {
+			seen[vi] = true
+		}
+		if len(labeling) != 6 || len(seen) != 6 {
+			t.Fatalf("expected the labeling to cover every component, got %v", labeling)
+		}
+	}
+	if len(vtx1) != 6 || len(edges1) != 4 {
+		t.Fatalf("expected every vertex and edge in the canonic form, got %v %v", vtx1, edges1)
+	}
+}
@@ -0,0 +1,356 @@
+/*
+Package server provides an embeddable net/http handler exposing go-orca over JSON, so non-Go clients can canonize
+graphs without linking Go.  All endpoints take a POST with a JSON body:
+
+    /canonize   {"graph": "1-2 2-3", "format": "text"}
+                  => {"canonic": "o1..", "graph": "1-2 1-3", "labeling": [2, 1, 3]}
+    /iso        {"a": "CCO", "b": "OCC", "format": "smiles"}
+                  => {"isomorphic": true, "a": "o1..", "b": "o1..", "mapping": [[1, 2], [2, 3], [3, 1]]}
+    /hash       {"graph": "c1ccccc1", "format": "smiles", "algo": "sha256"}
+                  => {"hash": "5c84.."}
+    /decode     {"canonic": "o1.."}
+                  => {"graph": "1-2 1-3", "num_vertices": 3, "num_edges": 2}
+
+Graphs are given as a string in one of: text (the default, see orca.ParseGraphText), dimacs, graph6 (also sparse6
+and digraph6), graphml, json (node-link), smiles, or sdf (the first record).  Returned graphs use the text notation.
+The hash algo is "base32" (the canonic string, the default) or "sha256" (of the canonic encoding, in hex).
+
+Errors are returned as {"error": ".."} with status 400 for bad input, 413 for a request over the size limit, and
+503 if canonization exceeds the per-request timeout or the handler is already running its limit of requests.
+*/
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/3x2theory/go-orca"
+	"github.com/3x2theory/go-orca/chem"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrTooLarge = errors.New("request too large")
+	ErrTimeout  = errors.New("request timed out")
+	ErrBusy     = errors.New("too many requests in progress")
+)
+
+// Opts controls a Handler.
+type Opts struct {
+	MaxRequestBytes int64         // default 1 MiB
+	Timeout         time.Duration // per-request limit on canonization (default 10s)
+	MaxConcurrent   int           // most requests doing work at once, including those that timed out (default NumCPU)
+	Attrs           orca.AttrMapping
+}
+
+var DefaultOpts = Opts{
+	MaxRequestBytes: 1 << 20,
+	Timeout:         10 * time.Second,
+	MaxConcurrent:   runtime.NumCPU(),
+	Attrs: orca.AttrMapping{
+		VtxAttr:  "color",
+		EdgeAttr: "color",
+	},
+}
+
+type handler struct {
+	opts  Opts
+	mux   *http.ServeMux
+	slots chan struct{} // holds a token for each request doing work
+}
+
+// NewHandler returns an http.Handler serving the go-orca endpoints.  Zero-valued options take their default.
+func NewHandler(opts Opts) http.Handler {
+	if opts.MaxRequestBytes <= 0 {
+		opts.MaxRequestBytes = DefaultOpts.MaxRequestBytes
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultOpts.Timeout
+	}
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = DefaultOpts.MaxConcurrent
+	}
+	if opts.Attrs == (orca.AttrMapping{}) {
+		opts.Attrs = DefaultOpts.Attrs
+	}
+	h := &handler{
+		opts:  opts,
+		mux:   http.NewServeMux(),
+		slots: make(chan struct{}, opts.MaxConcurrent),
+	}
+	h.mux.HandleFunc("/canonize", h.endpoint(h.canonize))
+	h.mux.HandleFunc("/iso", h.endpoint(h.iso))
+	h.mux.HandleFunc("/hash", h.endpoint(h.hash))
+	h.mux.HandleFunc("/decode", h.endpoint(h.decode))
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// endpoint wraps an endpoint's work with request decoding, the size limit, the timeout, the concurrency limit, and
+// error reporting.
+// The work function decodes the request body into its own request type and returns the response.
+func (h *handler) endpoint(work func(decode func(req interface{}) error) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{"POST required"})
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, h.opts.MaxRequestBytes+1))
+		if err == nil && int64(len(body)) > h.opts.MaxRequestBytes {
+			err = ErrTooLarge
+		}
+		if err != nil {
+			writeJSON(w, statusFor(err), errorResponse{err.Error()})
+			return
+		}
+		decode := func(req interface{}) error {
+			dec := json.NewDecoder(bytes.NewReader(body))
+			dec.DisallowUnknownFields()
+			return dec.Decode(req)
+		}
+
+		// The canonizer can't be interrupted, so an expired request is answered while its work finishes in the
+		// background.  Its slot is only released once the work returns, so abandoned work still counts against the limit.
+		select {
+		case h.slots <- struct{}{}:
+		default:
+			writeJSON(w, statusFor(ErrBusy), errorResponse{ErrBusy.Error()})
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), h.opts.Timeout)
+		defer cancel()
+		type result struct {
+			resp interface{}
+			err  error
+		}
+		done := make(chan result, 1)
+		go func() {
+			defer func() { <-h.slots }()
+			resp, err := work(decode)
+			done <- result{resp, err}
+		}()
+
+		select {
+		case res := <-done:
+			if res.err != nil {
+				writeJSON(w, statusFor(res.err), errorResponse{res.err.Error()})
+			} else {
+				writeJSON(w, http.StatusOK, res.resp)
+			}
+		case <-ctx.Done():
+			writeJSON(w, statusFor(ErrTimeout), errorResponse{ErrTimeout.Error()})
+		}
+	}
+}
+
+func statusFor(err error) int {
+	switch errors.Cause(err) {
+	case ErrTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case ErrTimeout, ErrBusy:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadRequest
+}
+
+// canonizeGraph parses a graph in the given format and returns its canonical form.
+func (h *handler) canonizeGraph(graph, format string) (*orca.Canonic, error) {
+	G, opts, err := h.parseGraph(graph, format)
+	if err != nil {
+		return nil, err
+	}
+	return orca.CanonizeGraph(orca.NewCanonizer(opts), G)
+}
+
+// parseGraph parses a graph in the given format, returning it with the canonizer options suited to it.
+func (h *handler) parseGraph(graph, format string) (*orca.Graph, orca.CanonizerOpts, error) {
+	var G *orca.Graph
+	var err error
+	opts := orca.DefaultCanonizerOpts
+
+	r := strings.NewReader(graph)
+	switch format {
+	case "", "text":
+		G, err = orca.ParseGraphText(graph)
+	case "dimacs":
+		G, err = orca.ReadDIMACS(r)
+	case "graph6", "sparse6", "digraph6":
+		G, err = orca.ParseGraph6([]byte(strings.TrimSpace(graph)))
+	case "graphml":
+		G, err = orca.ReadGraphML(r, h.opts.Attrs)
+	case "json":
+		G, err = orca.ReadNodeLink(r, h.opts.Attrs)
+	case "smiles":
+		G, err = chem.ParseSMILES(strings.TrimSpace(graph))
+		opts = chem.CanonizerOpts()
+	case "sdf":
+		var mol *chem.Molecule
+		if mol, err = chem.ReadMolfile(r); err == nil {
+			G = mol.Graph
+		}
+		opts = chem.CanonizerOpts()
+	default:
+		err = errors.Errorf("unknown format %q", format)
+	}
+	return G, opts, err
+}
+
+type graphRequest struct {
+	Graph  string `json:"graph"`
+	Format string `json:"format"`
+	Algo   string `json:"algo"`
+}
+
+type canonizeResponse struct {
+	Canonic  string          `json:"canonic"`
+	Graph    string          `json:"graph"`
+	Labeling []orca.VtxLabel `json:"labeling"`
+}
+
+func (h *handler) canonize(decode func(req interface{}) error) (interface{}, error) {
+	req := graphRequest{}
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	C, err := h.canonizeGraph(req.Graph, req.Format)
+	if err != nil {
+		return nil, err
+	}
+	return canonizeResponse{
+		Canonic:  C.CanonicString(),
+		Graph:    C.Graph.String(),
+		Labeling: C.Labeling,
+	}, nil
+}
+
+type hashResponse struct {
+	Hash string `json:"hash"`
+}
+
+func (h *handler) hash(decode func(req interface{}) error) (interface{}, error) {
+	req := graphRequest{}
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	if req.Algo != "" && req.Algo != "base32" && req.Algo != "sha256" {
+		return nil, errors.Errorf("unknown algo %q", req.Algo)
+	}
+	C, err := h.canonizeGraph(req.Graph, req.Format)
+	if err != nil {
+		return nil, err
+	}
+	if req.Algo == "sha256" {
+		sum := sha256.Sum256(C.Encoding())
+		return hashResponse{hex.EncodeToString(sum[:])}, nil
+	}
+	return hashResponse{C.CanonicString()}, nil
+}
+
+type isoRequest struct {
+	A      string `json:"a"`
+	B      string `json:"b"`
+	Format string `json:"format"`
+}
+
+type isoResponse struct {
+	Isomorphic bool   `json:"isomorphic"`
+	A          string `json:"a"`
+	B          string `json:"b"`
+
+	// If isomorphic, pairs of [a label, b label] (see orca.FindIsomorphism)
+	Mapping [][2]orca.VtxLabel `json:"mapping,omitempty"`
+}
+
+func (h *handler) iso(decode func(req interface{}) error) (interface{}, error) {
+	req := isoRequest{}
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	Ga, opts, err := h.parseGraph(req.A, req.Format)
+	if err != nil {
+		return nil, errors.Wrap(err, "a")
+	}
+	Gb, _, err := h.parseGraph(req.B, req.Format)
+	if err != nil {
+		return nil, errors.Wrap(err, "b")
+	}
+	canonizer := orca.NewCanonizer(opts)
+	Ca, err := orca.CanonizeGraph(canonizer, Ga)
+	if err != nil {
+		return nil, errors.Wrap(err, "a")
+	}
+	Cb, err := orca.CanonizeGraph(canonizer, Gb)
+	if err != nil {
+		return nil, errors.Wrap(err, "b")
+	}
+	resp := isoResponse{
+		A: Ca.CanonicString(),
+		B: Cb.CanonicString(),
+	}
+
+	mapping, err := orca.CanonicIsomorphism(Ca, Cb)
+	if errors.Cause(err) == orca.ErrNotIsomorphic {
+		return resp, nil
+	} else if err != nil {
+		return nil, err
+	}
+	resp.Isomorphic = true
+	resp.Mapping = make([][2]orca.VtxLabel, 0, len(mapping))
+	for va, vb := range mapping {
+		resp.Mapping = append(resp.Mapping, [2]orca.VtxLabel{va, vb})
+	}
+	sort.Slice(resp.Mapping, func(i, j int) bool {
+		return resp.Mapping[i][0] < resp.Mapping[j][0]
+	})
+	return resp, nil
+}
+
+type decodeRequest struct {
+	Canonic string `json:"canonic"`
+}
+
+type decodeResponse struct {
+	Graph       string `json:"graph"`
+	NumVertices int    `json:"num_vertices"`
+	NumEdges    int    `json:"num_edges"`
+}
+
+func (h *handler) decode(decode func(req interface{}) error) (interface{}, error) {
+	req := decodeRequest{}
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	G, err := orca.ParseCanonicGraph(req.Canonic)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponse{
+		Graph:       G.String(),
+		NumVertices: G.NumVerts(),
+		NumEdges:    G.NumEdges(),
+	}, nil
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/3x2theory/go-orca"
+)
+
+func post(t *testing.T, srv *httptest.Server, path, body string, resp interface{}) int {
+	res, err := http.Post(srv.URL+path, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if err = json.NewDecoder(res.Body).Decode(resp); err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode
+}
+
+func TestHandler(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(Opts{MaxRequestBytes: 200}))
+	defer srv.Close()
+
+	canon := canonizeResponse{}
+	if status := post(t, srv, "/canonize", `{"graph": "3-1 1-2 v3:4"}`, &canon); status != http.StatusOK {
+		t.Fatalf("unexpected status %d", status)
+	}
+	if canon.Graph != "v3:4 1-2 1-3" || len(canon.Labeling) != 3 {
+		t.Fatalf("unexpected canonize response %+v", canon)
+	}
+
+	decoded := decodeResponse{}
+	post(t, srv, "/decode", `{"canonic": "`+canon.Canonic+`"}`, &decoded)
+	if decoded.Graph != canon.Graph || decoded.NumVertices != 3 || decoded.NumEdges != 2 {
+		t.Fatalf("unexpected decode response %+v", decoded)
+	}
+
+	iso := isoResponse{}
+	post(t, srv, "/iso", `{"a": "OCC", "b": "C(O)C", "format": "smiles"}`, &iso)
+	if !iso.Isomorphic || len(iso.Mapping) != 3 || iso.Mapping[0] != [2]orca.VtxLabel{1, 2} {
+		t.Fatalf("unexpected iso response %+v", iso)
+	}
+	post(t, srv, "/iso", `{"a": "OCC", "b": "CCC", "format": "smiles"}`, &iso)
+	if iso.Isomorphic {
+		t.Fatalf("unexpected iso response %+v", iso)
+	}
+
+	hash := hashResponse{}
+	post(t, srv, "/hash", `{"graph": "1-2", "algo": "sha256"}`, &hash)
+	if len(hash.Hash) != 64 {
+		t.Fatalf("unexpected hash response %+v", hash)
+	}
+
+	errResp := errorResponse{}
+	if status := post(t, srv, "/canonize", `{"graph": "1-1"}`, &errResp); status != http.StatusBadRequest || errResp.Error == "" {
+		t.Fatalf("expected bad request, got %d", status)
+	}
+	if status := post(t, srv, "/canonize", `{"graph": "`+strings.Repeat("1-2 ", 100)+`"}`, &errResp); status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected request too large, got %d", status)
+	}
+}
+
+func TestHandlerTimeout(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(Opts{Timeout: time.Nanosecond}))
+	defer srv.Close()
+
+	// A cycle large enough that canonization can't beat the timeout
+	cycle := "1"
+	for i := 2; i <= 500; i++ {
+		cycle += fmt.Sprintf("-%d", i)
+	}
+	errResp := errorResponse{}
+	if status := post(t, srv, "/canonize", `{"graph": "`+cycle+`-1"}`, &errResp); status != http.StatusServiceUnavailable {
+		t.Fatalf("expected timeout, got %d", status)
+	}
+}
+
+func TestHandlerBusy(t *testing.T) {
+	h := NewHandler(Opts{MaxConcurrent: 1}).(*handler)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	// Hold the only slot, as a request whose work outlived its timeout would
+	h.slots <- struct{}{}
+	errResp := errorResponse{}
+	if status := post(t, srv, "/canonize", `{"graph": "1-2"}`, &errResp); status != http.StatusServiceUnavailable || errResp.Error != ErrBusy.Error() {
+		t.Fatalf("expected busy, got %d %q", status, errResp.Error)
+	}
+	<-h.slots
+	if status := post(t, srv, "/canonize", `{"graph": "1-2"}`, &canonizeResponse{}); status != http.StatusOK {
+		t.Fatalf("expected OK once the slot is free, got %d", status)
+	}
+}
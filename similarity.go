@@ -0,0 +1,190 @@
+package orca
+
+import (
+	"container/heap"
+	"math"
+	"math/bits"
+	"sort"
+	"sync"
+)
+
+// SimilarityMetric compares two fingerprints, giving a similarity in [0, 1].
+type SimilarityMetric int
+
+const (
+	MetricTanimoto SimilarityMetric = iota // |a & b| / |a | b|
+	MetricDice                             // 2 |a & b| / (|a| + |b|)
+	MetricCosine                           // |a & b| / sqrt(|a| |b|)
+)
+
+// Similarity returns the similarity of a and b, which is 0 if either has no bits set.
+func (m SimilarityMetric) Similarity(a, b Fingerprint) float64 {
+	return m.fromCounts(a.Count(), b.Count(), commonCount(a, b))
+}
+
+func (m SimilarityMetric) fromCounts(na, nb, common int) float64 {
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	switch m {
+	case MetricDice:
+		return 2 * float64(common) / float64(na+nb)
+	case MetricCosine:
+		return float64(common) / math.Sqrt(float64(na)*float64(nb))
+	}
+	return float64(common) / float64(na+nb-common)
+}
+
+// bound returns the highest similarity possible between fingerprints with the given bit counts.
+func (m SimilarityMetric) bound(na, nb int) float64 {
+	return m.fromCounts(na, nb, min(na, nb))
+}
+
+// TanimotoSimilarity returns the Tanimoto (Jaccard) similarity of a and b, the usual metric for fingerprints.
+func TanimotoSimilarity(a, b Fingerprint) float64 {
+	return MetricTanimoto.Similarity(a, b)
+}
+
+// TverskySimilarity returns |a & b| / (|a & b| + alpha |a - b| + beta |b - a|), where alpha = beta = 1 is Tanimoto and
+// alpha = 1, beta = 0 measures how much of a is contained in b.
+func TverskySimilarity(a, b Fingerprint, alpha, beta float64) float64 {
+	common := commonCount(a, b)
+	denom := float64(common) + alpha*float64(a.Count()-common) + beta*float64(b.Count()-common)
+	if denom == 0 {
+		return 0
+	}
+	return float64(common) / denom
+}
+
+// commonCount returns the number of bits set in both a and b.
+func commonCount(a, b Fingerprint) int {
+	common := 0
+	for i := 0; i < len(a) && i < len(b); i++ {
+		common += bits.OnesCount64(a[i] & b[i])
+	}
+	return common
+}
+
+// SimilarityHit is an entry found by a SimilarityIndex search.
+type SimilarityHit struct {
+	ID         string
+	Similarity float64
+}
+
+// SimilarityIndex holds fingerprints in memory for nearest neighbor and threshold searches.  Searches skip entries
+// whose bit count alone rules them out, but otherwise compare the query with every entry.
+//
+// A SimilarityIndex is safe for concurrent use.
+type SimilarityIndex struct {
+	metric SimilarityMetric
+	mu     sync.RWMutex
+	ids    []string
+	fps    []Fingerprint
+	counts []int
+}
+
+// NewSimilarityIndex returns an empty index comparing fingerprints with the given metric.
+func NewSimilarityIndex(metric SimilarityMetric) *SimilarityIndex {
+	return &SimilarityIndex{
+		metric: metric,
+	}
+}
+
+// Add adds a fingerprint under the given id, which need not be unique.
+func (idx *SimilarityIndex) Add(id string, fp Fingerprint) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.ids = append(idx.ids, id)
+	idx.fps = append(idx.fps, fp)
+	idx.counts = append(idx.counts, fp.Count())
+}
+
+// Len returns the number of fingerprints added.
+func (idx *SimilarityIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.ids)
+}
+
+// Threshold returns every entry at least minSimilarity similar to query, most similar first.
+func (idx *SimilarityIndex) Threshold(query Fingerprint, minSimilarity float64) []SimilarityHit {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	queryCount := query.Count()
+	var found hitHeap
+	for i, fp := range idx.fps {
+		if idx.metric.bound(queryCount, idx.counts[i]) < minSimilarity {
+			continue
+		}
+		if sim := idx.metric.fromCounts(queryCount, idx.counts[i], commonCount(query, fp)); sim >= minSimilarity {
+			found = append(found, indexedHit{i, sim})
+		}
+	}
+	return idx.hits(found)
+}
+
+// TopK returns the k entries most similar to query, most similar first, where ties go to the earliest added.
+func (idx *SimilarityIndex) TopK(query Fingerprint, k int) []SimilarityHit {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	if k <= 0 {
+		return nil
+	}
+
+	// found is a min heap of the best k so far, so its root is the one to displace
+	queryCount := query.Count()
+	found := make(hitHeap, 0, k)
+	for i, fp := range idx.fps {
+		if len(found) == k && idx.metric.bound(queryCount, idx.counts[i]) <= found[0].sim {
+			continue
+		}
+		hit := indexedHit{i, idx.metric.fromCounts(queryCount, idx.counts[i], commonCount(query, fp))}
+		if len(found) < k {
+			heap.Push(&found, hit)
+		} else if hit.sim > found[0].sim {
+			found[0] = hit
+			heap.Fix(&found, 0)
+		}
+	}
+	return idx.hits(found)
+}
+
+// hits sorts found by decreasing similarity (then insertion order) and returns them as SimilarityHits.
+func (idx *SimilarityIndex) hits(found hitHeap) []SimilarityHit {
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].sim != found[j].sim {
+			return found[i].sim > found[j].sim
+		}
+		return found[i].idx < found[j].idx
+	})
+	hits := make([]SimilarityHit, len(found))
+	for i, hit := range found {
+		hits[i] = SimilarityHit{idx.ids[hit.idx], hit.sim}
+	}
+	return hits
+}
+
+type indexedHit struct {
+	idx int
+	sim float64
+}
+
+// hitHeap is a min heap of hits, where among equal similarities the latest added is least.
+type hitHeap []indexedHit
+
+func (h hitHeap) Len() int { return len(h) }
+func (h hitHeap) Less(i, j int) bool {
+	if h[i].sim != h[j].sim {
+		return h[i].sim < h[j].sim
+	}
+	return h[i].idx > h[j].idx
+}
+func (h hitHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *hitHeap) Push(x interface{}) { *h = append(*h, x.(indexedHit)) }
+func (h *hitHeap) Pop() interface{} {
+	old := *h
+	hit := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return hit
+}
@@ -0,0 +1,85 @@
+package orca
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarityMetrics(t *testing.T) {
+	a := Fingerprint{0xF}  // 4 bits
+	b := Fingerprint{0x3C} // 4 bits, 2 in common with a
+	near := func(x, y float64) bool { return math.Abs(x-y) < 1e-9 }
+
+	if sim := TanimotoSimilarity(a, b); !near(sim, 2.0/6) {
+		t.Fatalf("unexpected Tanimoto similarity %v", sim)
+	}
+	if sim := MetricDice.Similarity(a, b); !near(sim, 0.5) {
+		t.Fatalf("unexpected Dice similarity %v", sim)
+	}
+	if sim := MetricCosine.Similarity(a, b); !near(sim, 0.5) {
+		t.Fatalf("unexpected cosine similarity %v", sim)
+	}
+	if sim := TverskySimilarity(a, b, 1, 1); !near(sim, TanimotoSimilarity(a, b)) {
+		t.Fatalf("expected Tversky(1, 1) to be Tanimoto, got %v", sim)
+	}
+	if sim := TverskySimilarity(Fingerprint{0x3}, a, 1, 0); !near(sim, 1) {
+		t.Fatalf("expected Tversky(1, 0) of a contained fingerprint to be 1, got %v", sim)
+	}
+	if TanimotoSimilarity(Fingerprint{0}, Fingerprint{0}) != 0 || TanimotoSimilarity(a, a) != 1 {
+		t.Fatal("unexpected similarity of empty or identical fingerprints")
+	}
+}
+
+func TestSimilarityIndex(t *testing.T) {
+	canonizer := NewCanonizer(CanonizerOpts{})
+	fingerprint := func(text string) Fingerprint {
+		G, err := ParseGraphText(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fp, err := GraphFingerprint(canonizer, G, DefaultFingerprintOpts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fp
+	}
+
+	idx := NewSimilarityIndex(MetricTanimoto)
+	idx.Add("hexagon", fingerprint("1-2 2-3 3-4 4-5 5-6 6-1"))
+	idx.Add("square", fingerprint("1-2 2-3 3-4 4-1"))
+	idx.Add("path", fingerprint("1-2 2-3 3-4 4-5 5-6"))
+	idx.Add("colored", fingerprint("1-(2)-2 2-(2)-3 v1:3 v2:3 v3:3"))
+	idx.Add("hexagon again", fingerprint("1-4 4-2 2-6 6-3 3-5 5-1"))
+	if idx.Len() != 5 {
+		t.Fatalf("expected 5 entries, got %d", idx.Len())
+	}
+
+	query := fingerprint("6-5 5-4 4-3 3-2 2-1 1-6")
+	hits := idx.TopK(query, 3)
+	if len(hits) != 3 || hits[0].ID != "hexagon" || hits[1].ID != "hexagon again" || hits[0].Similarity != 1 {
+		t.Fatalf("unexpected top hits %v", hits)
+	}
+	if hits[2].ID != "path" || hits[2].Similarity >= 1 {
+		t.Fatalf("expected the path to be next most similar, got %v", hits)
+	}
+
+	all := idx.TopK(query, 10)
+	if len(all) != 5 || all[4].ID != "colored" {
+		t.Fatalf("unexpected hits %v", all)
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].Similarity > all[i-1].Similarity {
+			t.Fatalf("expected hits in decreasing similarity, got %v", all)
+		}
+	}
+
+	if hits := idx.Threshold(query, 1); len(hits) != 2 || hits[0].ID != "hexagon" {
+		t.Fatalf("unexpected threshold hits %v", hits)
+	}
+	if hits := idx.Threshold(query, all[2].Similarity); len(hits) != 3 {
+		t.Fatalf("expected a threshold to include hits at it, got %v", hits)
+	}
+	if hits := idx.TopK(query, 0); len(hits) != 0 {
+		t.Fatal("expected no hits for k = 0")
+	}
+}
@@ -0,0 +1,241 @@
+package orca
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+/* Subgraph matching:
+
+MatchSubgraphs finds embeddings of a pattern graph in a target graph, i.e. injective maps of pattern vertices to
+target vertices that preserve vertex colors and where each pattern edge maps to a target edge of the same color.
+An induced match additionally requires that pattern vertices that are not adjacent map to target vertices that are
+not adjacent.
+
+In the style of VF2, pattern vertices are matched in a fixed order where each vertex (after the first of each
+connected component) is adjacent to one already matched, so candidates come from the neighbors of a matched target
+vertex rather than the whole target.  A candidate is accepted only if it agrees with every matched vertex.
+*/
+
+// MatchOpts controls subgraph matching.
+type MatchOpts struct {
+	Induced    bool // if set, non-adjacent pattern vertices must map to non-adjacent target vertices
+	MaxMatches int  // stop after this many matches (0 for no limit, 1 to stop after the first)
+}
+
+// matchGraph is a Graph indexed for matching, where vertices are referred to by index.
+type matchGraph struct {
+	labels    []VtxLabel
+	colors    []VtxColor
+	adj       []map[int]EdgeColor
+	neighbors [][]int // sorted
+}
+
+func newMatchGraph(G *Graph) (*matchGraph, error) {
+	M := &matchGraph{
+		labels: make([]VtxLabel, len(G.Vtx)),
+		colors: make([]VtxColor, len(G.Vtx)),
+		adj:    make([]map[int]EdgeColor, len(G.Vtx)),
+	}
+	idxOf := make(map[VtxLabel]int, len(G.Vtx))
+	for i, v := range G.Vtx {
+		M.labels[i] = v.Label
+		M.colors[i] = v.Color
+		M.adj[i] = make(map[int]EdgeColor)
+		idxOf[v.Label] = i
+	}
+	for _, e := range G.Edges {
+		a, foundA := idxOf[e.Va]
+		b, foundB := idxOf[e.Vb]
+		if !foundA || !foundB {
+			return nil, errors.Errorf("edge %d-%d references a missing vertex", e.Va, e.Vb)
+		}
+		if a == b {
+			return nil, errors.Errorf("loop at vertex %d is not supported", e.Va)
+		}
+		M.adj[a][b] = e.Color
+		M.adj[b][a] = e.Color
+	}
+	M.neighbors = make([][]int, len(G.Vtx))
+	for i, adj := range M.adj {
+		for j := range adj {
+			M.neighbors[i] = append(M.neighbors[i], j)
+		}
+		sort.Ints(M.neighbors[i])
+	}
+	return M, nil
+}
+
+type subgraphMatcher struct {
+	opts    MatchOpts
+	pattern *matchGraph
+	target  *matchGraph
+	order   []int // pattern vertices in match order
+	anchor  []int // for each pattern vertex, a pattern neighbor earlier in the order (or -1)
+	mapped  []int // pattern vertex => target vertex (or -1)
+	owner   []int // target vertex => pattern vertex (or -1)
+	matches int
+	fn      func(mapping map[VtxLabel]VtxLabel) bool
+	stopped bool
+}
+
+// MatchSubgraphs calls fn with each embedding of pattern in target, as a map from pattern to target VtxLabels,
+// until fn returns false or opts.MaxMatches is reached.
+func MatchSubgraphs(pattern, target *Graph, opts MatchOpts, fn func(mapping map[VtxLabel]VtxLabel) bool) error {
+	P, err := newMatchGraph(pattern)
+	if err != nil {
+		return errors.Wrap(err, "pattern")
+	}
+	T, err := newMatchGraph(target)
+	if err != nil {
+		return errors.Wrap(err, "target")
+	}
+	if len(P.labels) > len(T.labels) {
+		return nil
+	}
+
+	m := &subgraphMatcher{
+		opts:    opts,
+		pattern: P,
+		target:  T,
+		mapped:  make([]int, len(P.labels)),
+		owner:   make([]int, len(T.labels)),
+		fn:      fn,
+	}
+	for i := range m.mapped {
+		m.mapped[i] = -1
+	}
+	for i := range m.owner {
+		m.owner[i] = -1
+	}
+	m.orderPattern()
+	m.match(0)
+	return nil
+}
+
+// FindSubgraphs returns the embeddings of pattern in target (see MatchSubgraphs).
+func FindSubgraphs(pattern, target *Graph, opts MatchOpts) ([]map[VtxLabel]VtxLabel, error) {
+	var found []map[VtxLabel]VtxLabel
+	err := MatchSubgraphs(pattern, target, opts, func(mapping map[VtxLabel]VtxLabel) bool {
+		found = append(found, mapping)
+		return true
+	})
+	return found, err
+}
+
+// orderPattern orders pattern vertices breadth first, starting each component at its most constrained vertex
+// (highest degree, with the pattern's rarest colors preferred among ties).
+func (m *subgraphMatcher) orderPattern() {
+	P := m.pattern
+	N := len(P.labels)
+
+	colorCount := make(map[VtxColor]int)
+	for _, color := range m.target.colors {
+		colorCount[color]++
+	}
+	byConstraint := make([]int, N)
+	for i := range byConstraint {
+		byConstraint[i] = i
+	}
+	sort.SliceStable(byConstraint, func(i, j int) bool {
+		vi, vj := byConstraint[i], byConstraint[j]
+		if len(P.adj[vi]) != len(P.adj[vj]) {
+			return len(P.adj[vi]) > len(P.adj[vj])
+		}
+		return colorCount[P.colors[vi]] < colorCount[P.colors[vj]]
+	})
+
+	m.anchor = make([]int, N)
+	placed := make([]bool, N)
+	for _, root := range byConstraint {
+		if placed[root] {
+			continue
+		}
+		placed[root] = true
+		m.anchor[root] = -1
+		m.order = append(m.order, root)
+		for qi := len(m.order) - 1; qi < len(m.order); qi++ {
+			vi := m.order[qi]
+			for _, vj := range P.neighbors[vi] {
+				if placed[vj] {
+					continue
+				}
+				placed[vj] = true
+				m.anchor[vj] = vi
+				m.order = append(m.order, vj)
+			}
+		}
+	}
+}
+
+func (m *subgraphMatcher) match(depth int) {
+	if depth == len(m.order) {
+		m.emit()
+		return
+	}
+
+	pv := m.order[depth]
+	if anchor := m.anchor[pv]; anchor >= 0 {
+		for _, tv := range m.target.neighbors[m.mapped[anchor]] {
+			if m.stopped {
+				return
+			}
+			m.tryCandidate(depth, pv, tv)
+		}
+	} else {
+		for tv := range m.target.labels {
+			if m.stopped {
+				return
+			}
+			m.tryCandidate(depth, pv, tv)
+		}
+	}
+}
+
+func (m *subgraphMatcher) tryCandidate(depth, pv, tv int) {
+	if m.owner[tv] >= 0 || !m.feasible(pv, tv) {
+		return
+	}
+	m.mapped[pv] = tv
+	m.owner[tv] = pv
+	m.match(depth + 1)
+	m.mapped[pv] = -1
+	m.owner[tv] = -1
+}
+
+// feasible returns true if mapping pattern vertex pv to target vertex tv agrees with all vertices mapped so far.
+func (m *subgraphMatcher) feasible(pv, tv int) bool {
+	P, T := m.pattern, m.target
+	if P.colors[pv] != T.colors[tv] || len(P.adj[pv]) > len(T.adj[tv]) {
+		return false
+	}
+	for pw, color := range P.adj[pv] {
+		if tw := m.mapped[pw]; tw >= 0 {
+			if tcolor, adjacent := T.adj[tv][tw]; !adjacent || tcolor != color {
+				return false
+			}
+		}
+	}
+	if m.opts.Induced {
+		for _, tw := range T.neighbors[tv] {
+			if pw := m.owner[tw]; pw >= 0 {
+				if _, adjacent := P.adj[pv][pw]; !adjacent {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func (m *subgraphMatcher) emit() {
+	mapping := make(map[VtxLabel]VtxLabel, len(m.mapped))
+	for pv, tv := range m.mapped {
+		mapping[m.pattern.labels[pv]] = m.target.labels[tv]
+	}
+	m.matches++
+	if !m.fn(mapping) || (m.opts.MaxMatches > 0 && m.matches >= m.opts.MaxMatches) {
+		m.stopped = true
+	}
+}
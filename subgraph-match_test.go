@@ -0,0 +1,41 @@
+package orca
+
+import (
+	"testing"
+)
+
+func TestMatchSubgraphs(t *testing.T) {
+	path, _ := ParseGraphText("1-2 2-3")
+	triangle, _ := ParseGraphText("1-2 2-3 3-1")
+
+	// A path of 3 embeds in a triangle 6 ways, but never as an induced subgraph
+	found, err := FindSubgraphs(path, triangle, MatchOpts{})
+	if err != nil || len(found) != 6 {
+		t.Fatalf("expected 6 matches, got %d (%v)", len(found), err)
+	}
+	if found, _ = FindSubgraphs(path, triangle, MatchOpts{Induced: true}); len(found) != 0 {
+		t.Fatalf("expected no induced matches, got %d", len(found))
+	}
+	if found, _ = FindSubgraphs(path, triangle, MatchOpts{MaxMatches: 1}); len(found) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(found))
+	}
+
+	// Colors: C=O on a C-C(=O)-O fragment with a disconnected N
+	pattern, _ := ParseGraphText("v1:6 v2:8 1-(2)-2")
+	target, _ := ParseGraphText("v1:6 v2:6 v3:8 v4:8 v5:7 1-2 2-(2)-3 2-4")
+	found, err = FindSubgraphs(pattern, target, MatchOpts{})
+	if err != nil || len(found) != 1 || found[0][1] != 2 || found[0][2] != 3 {
+		t.Fatalf("unexpected matches %v (%v)", found, err)
+	}
+
+	// A disconnected pattern: an edge plus an isolated vertex colored 7
+	pattern, _ = ParseGraphText("v3:7 1-2")
+	if found, _ = FindSubgraphs(pattern, target, MatchOpts{}); len(found) != 0 {
+		t.Fatalf("expected no matches for uncolored edge, got %d", len(found))
+	}
+	pattern, _ = ParseGraphText("v1:6 v2:6 v3:7 1-2")
+	found, _ = FindSubgraphs(pattern, target, MatchOpts{Induced: true})
+	if len(found) != 2 || found[0][3] != 5 {
+		t.Fatalf("unexpected matches %v", found)
+	}
+}
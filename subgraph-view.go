@@ -0,0 +1,107 @@
+package orca
+
+import (
+	"github.com/pkg/errors"
+)
+
+// SelfSubGraph returns the SubGraph of the graph the canonizer most recently built (as edited) with all its edges.
+// Like those from FetchSubGraph, it's drawn from the canonizer's subgraph catalog, so it's only valid until the next
+// BuildGraph.
+func SelfSubGraph(canonizer IGraphCanonizer) (SubGraph, error) {
+	ctx, err := encoderFor(canonizer)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.SelfSubGraph(), nil
+}
+
+// FetchSubGraph returns the SubGraph of the graph the canonizer most recently built with the edges of an existing
+// SubGraph's EdgeSet less removeEdges.
+func FetchSubGraph(canonizer IGraphCanonizer, from EdgeSet, removeEdges []Edge) (SubGraph, error) {
+	ctx, err := encoderFor(canonizer)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.FetchSubGraph(from, removeEdges)
+}
+
+// CanonizeSubGraph returns the canonical form of the view of the graph the canonizer most recently built having
+// subG's edges and the given vertices (or every vertex if vtx is nil), where edges to vertices outside the view are
+// dropped.  For example, passing SelfSubGraph canonizes the subgraph induced by vtx.  The graph isn't rebuilt, so
+// work is shared across views through the subgraph catalog.
+func CanonizeSubGraph(canonizer IGraphCanonizer, subG SubGraph, vtx []VtxLabel) (*Canonic, error) {
+	ctx, err := encoderFor(canonizer)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.CanonizeSubGraph(subG, vtx)
+}
+
+// CanonizeSubGraph -- see CanonizeSubGraph()
+func (ctx *encoderCtx) CanonizeSubGraph(subG SubGraph, vtx []VtxLabel) (*Canonic, error) {
+	if ctx.Error() != nil {
+		return nil, ctx.Error()
+	}
+	view, ok := subG.(*subGraph)
+	if !ok || len(view.edgeSet) != len(ctx.edgeSetTmp) {
+		return nil, errors.New("SubGraph is not from the most recently built graph")
+	}
+
+	// Vertices outside the view are removed along with their edges, like an edit that is undone afterwards
+	removedVtx := make(map[VtxLabel]bool, len(ctx.removedVtx))
+	for vi := range ctx.removedVtx {
+		removedVtx[vi] = true
+	}
+	if vtx != nil {
+		inView := make(map[VtxLabel]bool, len(vtx))
+		for _, vi := range vtx {
+			if !ctx.isVtxPresent(vi) {
+				return nil, errors.Errorf("VtxLabel %d not found", vi)
+			}
+			inView[vi] = true
+		}
+		var removeEdges []Edge
+		for _, v := range ctx.vtx {
+			if inView[v.VtxLabel] {
+				continue
+			}
+			removedVtx[v.VtxLabel] = true
+			for _, edge := range v.edges {
+				ce := edge.FormCanonicalEdge(v.VtxLabel)
+				// Edges between two removed vertices are seen from both ends, so only remove them once
+				if ctx.IsEdgePresent(view.edgeSet, ce) && (inView[edge.toVtx] || v.VtxLabel < edge.toVtx) {
+					removeEdges = append(removeEdges, Edge(ce))
+				}
+			}
+		}
+		fetched, err := ctx.FetchSubGraph(view.edgeSet, removeEdges)
+		if err != nil {
+			return nil, err
+		}
+		view = fetched.(*subGraph)
+	}
+
+	editMask, editRemovedVtx := ctx.editMask, ctx.removedVtx
+	ctx.editMask, ctx.removedVtx = view.edgeSet, removedVtx
+	labeling, err := ctx.CanonicLabeling()
+	ctx.editMask, ctx.removedVtx = editMask, editRemovedVtx
+	if err != nil {
+		return nil, err
+	}
+
+	G := &Graph{}
+	for _, v := range ctx.vtx {
+		if !removedVtx[v.VtxLabel] {
+			G.Vtx = append(G.Vtx, Vtx{
+				Label: v.VtxLabel,
+				Color: v.VtxColor,
+			})
+		}
+	}
+	for _, e := range ctx.edges {
+		if ctx.IsEdgePresent(view.edgeSet, e) {
+			G.Edges = append(G.Edges, Edge(e))
+		}
+	}
+	return relabel(G, labeling), nil
+}
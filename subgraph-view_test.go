@@ -0,0 +1,69 @@
+package orca
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCanonizeSubGraph(t *testing.T) {
+	parse := func(text string) *Graph {
+		G, err := ParseGraphText(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return G
+	}
+	expect := func(C *Canonic, err error, text string) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		fresh, err := CanonizeGraph(NewCanonizer(DefaultCanonizerOpts), parse(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(C.Encoding(), fresh.Encoding()) {
+			t.Fatalf("expected %v, got %v", fresh.Graph.String(), C.Graph.String())
+		}
+	}
+
+	// A hexagon with a colored pendant on vertex 1
+	G := parse("v7:8 1-2 2-3 3-4 4-5 5-6 6-1 1-(2)-7")
+	canonizer := NewCanonizer(DefaultCanonizerOpts)
+	if _, err := CanonizeGraph(canonizer, G); err != nil {
+		t.Fatal(err)
+	}
+	self, err := SelfSubGraph(canonizer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Edge-masked: opening the ring
+	open, err := FetchSubGraph(canonizer, self.EdgeSet(), []Edge{{Va: 3, Vb: 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	C, err := CanonizeSubGraph(canonizer, open, nil)
+	expect(C, err, "v7:8 1-2 2-3 4-5 5-6 6-1 1-(2)-7")
+
+	// Vertex-masked: the subgraph induced by 1, 2, 6 and 7
+	C, err = CanonizeSubGraph(canonizer, self, []VtxLabel{1, 2, 6, 7})
+	expect(C, err, "v4:8 1-2 1-3 1-(2)-4")
+	if C.NumVerts() != 4 || C.NumEdges() != 3 {
+		t.Fatalf("unexpected induced subgraph %v", C.Graph.String())
+	}
+
+	// Both: the opened ring restricted to 2..5
+	C, err = CanonizeSubGraph(canonizer, open, []VtxLabel{2, 3, 4, 5})
+	expect(C, err, "1-2 3-4")
+
+	// The whole graph is unaffected by the views
+	C, err = CanonizeSubGraph(canonizer, self, nil)
+	expect(C, err, "v7:8 1-2 2-3 3-4 4-5 5-6 6-1 1-(2)-7")
+
+	if _, err = CanonizeSubGraph(canonizer, self, []VtxLabel{1, 9}); err == nil {
+		t.Fatal("expected an error for an unknown vertex")
+	}
+	if _, err = FetchSubGraph(canonizer, open.EdgeSet(), []Edge{{Va: 3, Vb: 4}}); err == nil {
+		t.Fatal("expected an error for removing a missing edge")
+	}
+}
@@ -1,8 +1,7 @@
 package orca
 
 import (
-	"fmt"
-	"strings"
+	"github.com/pkg/errors"
 )
 
 
@@ -11,6 +10,10 @@ import (
 type CanonizerOpts struct {
     SubGraphLimit int64
     SoftInfinity  bool
+
+    // If set, these registries are attached to the canonizer (otherwise an empty registry is created for each).
+    VtxColors     *VtxColorRegistry
+    EdgeColors    *EdgeColorRegistry
 }
 
 
@@ -39,6 +42,10 @@ func NewCanonizer(opts CanonizerOpts) IGraphCanonizer {
 // A VtxLabel value of 0 is considered nil/invalid.
 type VtxLabel uint32
 
+// MaxParsedVtx is the most vertices (or highest VtxLabel) a parser in this package accepts before reading a graph's
+// edges, so that a few bytes of untrusted input can't demand an enormous allocation.
+var MaxParsedVtx = 1 << 20
+
 // VtxColor is a client-chosen value that expressed an immutable vertex flavor/class.
 // A VtxColor assignment is client-defined and must be >= 0 (negative values are reserved internally).
 type VtxColor int64
@@ -177,18 +184,9 @@ func (Gin GraphIn) Consume(handler func (v Vtx, e Edge)) {
 
 
 
+// String consumes Gin and returns it in the graph text notation (see ParseGraphText).
 func (Gin GraphIn) String() string {
-    buf := &strings.Builder{}
-
-	Gin.Consume(func (v Vtx, e Edge) {
-        if v.Label != 0 {
-            fmt.Fprintf(buf, "v%d: %d  ", v.Label, v.Color)
-        } else {
-            fmt.Fprintf(buf, "%d-(%d)-%d, ", e.Va, e.Color, e.Vb)
-        }
-    })
-    
-    return buf.String()
+    return ReadGraph(Gin).String()
 }
 
 
@@ -211,6 +209,20 @@ type IGraphCanonizer interface {
 
 }
 
+// ErrUnsupportedCanonizer is returned by operations beyond IGraphCanonizer (such as CanonicLabeling) when given a
+// canonizer that wasn't made by NewCanonizer.
+var ErrUnsupportedCanonizer = errors.New("operation requires a canonizer made by NewCanonizer")
+
+// encoderFor returns the encoderCtx behind a canonizer made by NewCanonizer, which implements the operations that
+// are kept out of IGraphCanonizer so that other implementations of it aren't broken as features are added.
+func encoderFor(canonizer IGraphCanonizer) (*encoderCtx, error) {
+    ctx, ok := canonizer.(*encoderCtx)
+    if !ok {
+        return nil, ErrUnsupportedCanonizer
+    }
+    return ctx, nil
+}
+
 
 
 // // IGraphEncoder performs canonical encoding of any general graph.